@@ -0,0 +1,249 @@
+package annotator
+
+import (
+	"fmt"
+	"github.com/getcharzp/go-vision"
+	"github.com/up-zero/gotool/imageutil"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Config 绘制参数
+type Config struct {
+	Palette   []color.RGBA // 类别颜色表，按类别 ID 循环取色 (默认 DefaultPalette)
+	Thickness int          // 线宽 (像素)，<= 0 时根据图片尺寸计算
+	Face      font.Face    // 标签字体，nil 时使用 basicfont.Face7x13 (仅支持 ASCII)
+
+	HideBoxes  bool    // 不绘制检测框和标签，适用于只关注 Mask 或骨架的场景
+	HideLabels bool    // 不绘制标签
+	HideScores bool    // 标签中不显示得分
+	MaskAlpha  float64 // Mask 叠加的不透明度，取值 [0, 1] (默认 0.5)
+
+	Skeleton          [][2]int   // 骨架连接 (默认 vision.COCOSkeleton)
+	SkeletonColor     color.RGBA // 骨架颜色 (默认绿色)
+	KeyPointColor     color.RGBA // 关键点颜色 (默认红色)
+	KeyPointRadius    int        // 关键点半径 (像素)，<= 0 时为线宽的 2 倍
+	KeyPointThreshold float32    // 置信度大于该值的关键点才会绘制 (默认 0.5)
+}
+
+// DefaultConfig 默认绘制参数
+func DefaultConfig() Config {
+	return Config{
+		Palette:           DefaultPalette,
+		MaskAlpha:         0.5,
+		Skeleton:          vision.COCOSkeleton,
+		SkeletonColor:     color.RGBA{G: 255, A: 255},
+		KeyPointColor:     color.RGBA{R: 255, A: 255},
+		KeyPointThreshold: 0.5,
+	}
+}
+
+// Annotator 将推理结果绘制到图片上
+//
+// 字体 (font.Face) 不支持并发使用，多个 goroutine 同时绘制时应各自创建 Annotator
+type Annotator struct {
+	config Config
+}
+
+// New 创建 Annotator
+func New(cfg Config) *Annotator {
+	if len(cfg.Palette) == 0 {
+		cfg.Palette = DefaultPalette
+	}
+	if cfg.Face == nil {
+		cfg.Face = basicfont.Face7x13
+	}
+	return &Annotator{config: cfg}
+}
+
+// Color 类别对应的颜色
+func (a *Annotator) Color(classID int) color.RGBA {
+	n := len(a.config.Palette)
+	return a.config.Palette[((classID%n)+n)%n]
+}
+
+// DrawDetections 绘制目标检测结果，返回新的图片
+func (a *Annotator) DrawDetections(img image.Image, results []vision.DetResult) *image.RGBA {
+	dst, thickness := a.canvas(img)
+	for _, res := range results {
+		a.drawBox(dst, res.Box, res.ClassID, res.ClassName, res.Score, thickness)
+	}
+	return dst
+}
+
+// DrawSegmentations 绘制分割结果，Mask 半透明叠加在图片上，返回新的图片
+//
+// Mask 的尺寸 (MaskSize) 与图片不一致时按比例缩放
+func (a *Annotator) DrawSegmentations(img image.Image, results []vision.SegResult) *image.RGBA {
+	dst, thickness := a.canvas(img)
+	for _, res := range results {
+		a.drawMask(dst, res.Mask, res.MaskSize, a.Color(res.ClassID))
+	}
+	for _, res := range results {
+		a.drawBox(dst, res.Box, res.ClassID, res.ClassName, res.Score, thickness)
+	}
+	return dst
+}
+
+// DrawOBBs 绘制旋转目标检测结果，返回新的图片
+func (a *Annotator) DrawOBBs(img image.Image, results []vision.OBBResult) *image.RGBA {
+	dst, thickness := a.canvas(img)
+	if a.config.HideBoxes {
+		return dst
+	}
+	for _, res := range results {
+		c := a.Color(res.ClassID)
+		imageutil.DrawThickPolygonOutline(dst, res.Corners[:], thickness, c)
+		if !a.config.HideLabels {
+			// 标签位于最上方的顶点
+			top := res.Corners[0]
+			for _, p := range res.Corners[1:] {
+				if p.Y < top.Y || (p.Y == top.Y && p.X < top.X) {
+					top = p
+				}
+			}
+			a.drawLabel(dst, a.labelText(res.ClassID, res.ClassName, res.Score), image.Rectangle{Min: top, Max: top}, c)
+		}
+	}
+	return dst
+}
+
+// DrawPoses 绘制姿态估计结果 (检测框、骨架、关键点)，返回新的图片
+func (a *Annotator) DrawPoses(img image.Image, results []vision.PoseResult) *image.RGBA {
+	dst, thickness := a.canvas(img)
+	radius := a.config.KeyPointRadius
+	if radius <= 0 {
+		radius = thickness * 2
+	}
+	for _, res := range results {
+		a.drawBox(dst, res.Box, res.ClassID, res.ClassName, res.Score, thickness)
+
+		kpts := res.KeyPoints
+		visible := func(i int) bool {
+			return i >= 0 && i < len(kpts) && kpts[i].Score > a.config.KeyPointThreshold
+		}
+		for _, pair := range a.config.Skeleton {
+			if visible(pair[0]) && visible(pair[1]) {
+				kpA, kpB := kpts[pair[0]], kpts[pair[1]]
+				imageutil.DrawThickLine(dst, image.Pt(kpA.X, kpA.Y), image.Pt(kpB.X, kpB.Y), thickness, a.config.SkeletonColor)
+			}
+		}
+		for i, kp := range kpts {
+			if visible(i) {
+				imageutil.DrawFilledCircle(dst, image.Pt(kp.X, kp.Y), radius, a.config.KeyPointColor)
+			}
+		}
+	}
+	return dst
+}
+
+// canvas 复制原图并计算线宽
+func (a *Annotator) canvas(img image.Image) (*image.RGBA, int) {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+	return dst, a.thickness(b.Size())
+}
+
+// thickness 线宽，未指定时为图片宽高均值的 0.3%，至少 2 像素
+func (a *Annotator) thickness(size image.Point) int {
+	if a.config.Thickness > 0 {
+		return a.config.Thickness
+	}
+	return max(int(math.Round(float64(size.X+size.Y)/2*0.003)), 2)
+}
+
+// drawBox 绘制检测框和标签
+func (a *Annotator) drawBox(dst *image.RGBA, box image.Rectangle, classID int, className string, score float32, thickness int) {
+	if a.config.HideBoxes {
+		return
+	}
+	c := a.Color(classID)
+	imageutil.DrawThickRectOutline(dst, box, c, thickness)
+	if !a.config.HideLabels {
+		a.drawLabel(dst, a.labelText(classID, className, score), box, c)
+	}
+}
+
+// labelText 标签文本，例如 person 0.92
+func (a *Annotator) labelText(classID int, className string, score float32) string {
+	if className == "" {
+		className = fmt.Sprint(classID)
+	}
+	if a.config.HideScores {
+		return className
+	}
+	return fmt.Sprintf("%s %.2f", className, score)
+}
+
+// drawLabel 在 box 左上角绘制带背景的标签，上方空间不足时绘制在框内，水平方向限制在图片范围内
+func (a *Annotator) drawLabel(dst *image.RGBA, text string, box image.Rectangle, bg color.RGBA) {
+	face := a.config.Face
+	metrics := face.Metrics()
+	ascent, descent := metrics.Ascent.Ceil(), metrics.Descent.Ceil()
+	pad := max(ascent/6, 1)
+	w := font.MeasureString(face, text).Ceil() + pad*2
+	h := ascent + descent + pad*2
+
+	bounds := dst.Bounds()
+	x := min(box.Min.X, bounds.Max.X-w)
+	x = max(x, bounds.Min.X)
+	y := box.Min.Y - h
+	if y < bounds.Min.Y {
+		y = max(box.Min.Y, bounds.Min.Y)
+	}
+	bgRect := image.Rect(x, y, x+w, y+h)
+	imageutil.DrawFilledRect(dst, bgRect, bg)
+
+	d := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(textColor(bg)),
+		Face: face,
+		Dot:  fixed.P(x+pad, y+pad+ascent),
+	}
+	d.DrawString(text)
+}
+
+// drawMask 将 Mask 以半透明颜色叠加到图片上
+//
+// # Params:
+//
+//	dst: 画布
+//	mask: 只保存检测框区域的 Mask，Bounds 为 Mask 坐标
+//	maskSize: Mask 坐标系下整幅图片的尺寸
+//	c: 叠加的颜色
+func (a *Annotator) drawMask(dst *image.RGBA, mask *image.Gray, maskSize image.Point, c color.RGBA) {
+	if mask == nil || maskSize.X <= 0 || maskSize.Y <= 0 {
+		return
+	}
+	alpha := min(max(a.config.MaskAlpha, 0), 1)
+	b := dst.Bounds()
+	sx := float64(maskSize.X) / float64(b.Dx())
+	sy := float64(maskSize.Y) / float64(b.Dy())
+
+	// Mask 区域映射到图片坐标
+	r := image.Rect(
+		int(math.Floor(float64(mask.Rect.Min.X)/sx)), int(math.Floor(float64(mask.Rect.Min.Y)/sy)),
+		int(math.Ceil(float64(mask.Rect.Max.X)/sx)), int(math.Ceil(float64(mask.Rect.Max.Y)/sy)),
+	).Add(b.Min).Intersect(b)
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		my := int((float64(y-b.Min.Y) + 0.5) * sy)
+		for x := r.Min.X; x < r.Max.X; x++ {
+			mx := int((float64(x-b.Min.X) + 0.5) * sx)
+			if !image.Pt(mx, my).In(mask.Rect) || mask.Pix[mask.PixOffset(mx, my)] <= 127 {
+				continue
+			}
+			i := dst.PixOffset(x, y)
+			p := dst.Pix[i : i+3 : i+3]
+			p[0] = uint8(float64(p[0])*(1-alpha) + float64(c.R)*alpha)
+			p[1] = uint8(float64(p[1])*(1-alpha) + float64(c.G)*alpha)
+			p[2] = uint8(float64(p[2])*(1-alpha) + float64(c.B)*alpha)
+		}
+	}
+}
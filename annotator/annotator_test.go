@@ -0,0 +1,130 @@
+package annotator
+
+import (
+	"github.com/getcharzp/go-vision"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// grayImage 纯色测试图片
+func grayImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 128
+	}
+	return img
+}
+
+func TestAnnotator_Color(t *testing.T) {
+	a := New(DefaultConfig())
+	if a.Color(0) != DefaultPalette[0] || a.Color(len(DefaultPalette)+1) != DefaultPalette[1] {
+		t.Error("类别颜色应按类别 ID 循环取色")
+	}
+	if a.Color(-1) != DefaultPalette[len(DefaultPalette)-1] {
+		t.Error("负数类别 ID 不应越界")
+	}
+}
+
+func TestAnnotator_Thickness(t *testing.T) {
+	a := New(DefaultConfig())
+	if got := a.thickness(image.Pt(1920, 1080)); got != 5 {
+		t.Errorf("1920x1080 的线宽应为 5，实际 %d", got)
+	}
+	if got := a.thickness(image.Pt(100, 100)); got != 2 {
+		t.Errorf("线宽至少为 2，实际 %d", got)
+	}
+}
+
+func TestAnnotator_DrawDetections(t *testing.T) {
+	img := grayImage(200, 100)
+	a := New(DefaultConfig())
+	res := vision.DetResult{ClassID: 2, ClassName: "car", Score: 0.9, Box: image.Rect(50, 40, 150, 90)}
+	dst := a.DrawDetections(img, []vision.DetResult{res})
+
+	c := a.Color(2)
+	if got := dst.RGBAAt(50, 60); got != c {
+		t.Errorf("检测框左边应为类别颜色 %v，实际 %v", c, got)
+	}
+	if got := dst.RGBAAt(100, 60); got != img.RGBAAt(100, 60) {
+		t.Errorf("检测框内部不应被修改，实际 %v", got)
+	}
+	// 标签背景位于检测框上方
+	if got := dst.RGBAAt(51, 39); got != c {
+		t.Errorf("标签背景应为类别颜色 %v，实际 %v", c, got)
+	}
+	// 原图不被修改
+	if img.RGBAAt(50, 60) != (color.RGBA{128, 128, 128, 128}) {
+		t.Error("原图被修改")
+	}
+}
+
+func TestAnnotator_LabelClamp(t *testing.T) {
+	// 检测框贴近右上角时，标签位于框内并限制在图片范围内
+	img := grayImage(100, 100)
+	a := New(DefaultConfig())
+	box := image.Rect(90, 0, 100, 50)
+	dst := a.DrawDetections(img, []vision.DetResult{{ClassName: "person", Score: 0.5, Box: box}})
+
+	c := a.Color(0)
+	if got := dst.RGBAAt(60, 3); got != c {
+		t.Errorf("标签应向左移动到图片范围内，(60, 3) 为 %v", got)
+	}
+}
+
+func TestAnnotator_DrawSegmentations(t *testing.T) {
+	img := grayImage(40, 40)
+	cfg := DefaultConfig()
+	cfg.HideBoxes = true
+	a := New(cfg)
+
+	// Mask 为原图尺寸的一半，覆盖 [5, 10) x [5, 10)
+	mask := image.NewGray(image.Rect(4, 4, 12, 12))
+	for y := 5; y < 10; y++ {
+		for x := 5; x < 10; x++ {
+			mask.Pix[mask.PixOffset(x, y)] = 255
+		}
+	}
+	res := vision.SegResult{ClassID: 1, Mask: mask, MaskSize: image.Pt(20, 20)}
+	dst := a.DrawSegmentations(img, []vision.SegResult{res})
+
+	c := a.Color(1)
+	want := color.RGBA{
+		R: uint8(128*0.5 + float64(c.R)*0.5),
+		G: uint8(128*0.5 + float64(c.G)*0.5),
+		B: uint8(128*0.5 + float64(c.B)*0.5),
+		A: 128,
+	}
+	if got := dst.RGBAAt(15, 15); got != want {
+		t.Errorf("Mask 内的像素应为 %v，实际 %v", want, got)
+	}
+	if got := dst.RGBAAt(25, 25); got != img.RGBAAt(25, 25) {
+		t.Errorf("Mask 外的像素不应被修改，实际 %v", got)
+	}
+}
+
+func TestAnnotator_DrawPoses(t *testing.T) {
+	img := grayImage(100, 100)
+	cfg := DefaultConfig()
+	cfg.HideBoxes = true
+	cfg.Thickness = 2
+	a := New(cfg)
+
+	// 关键点数量少于骨架索引时不应越界
+	res := vision.PoseResult{KeyPoints: []vision.KeyPoint{
+		{X: 20, Y: 20, Score: 0.9},
+		{X: 80, Y: 20, Score: 0.9},
+		{X: 50, Y: 80, Score: 0.1},
+	}}
+	dst := a.DrawPoses(img, []vision.PoseResult{res})
+
+	if got := dst.RGBAAt(20, 20); got != cfg.KeyPointColor {
+		t.Errorf("可见关键点应为 %v，实际 %v", cfg.KeyPointColor, got)
+	}
+	if got := dst.RGBAAt(50, 20); got != cfg.SkeletonColor {
+		t.Errorf("骨架 (0, 1) 应为 %v，实际 %v", cfg.SkeletonColor, got)
+	}
+	if got := dst.RGBAAt(50, 80); got != img.RGBAAt(50, 80) {
+		t.Errorf("低置信度的关键点不应绘制，实际 %v", got)
+	}
+}
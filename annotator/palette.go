@@ -0,0 +1,26 @@
+package annotator
+
+import "image/color"
+
+// DefaultPalette 默认的类别颜色表，与 Ultralytics 的配色一致
+var DefaultPalette = []color.RGBA{
+	hex(0xFF3838), hex(0xFF9D97), hex(0xFF701F), hex(0xFFB21D), hex(0xCFD231),
+	hex(0x48F90A), hex(0x92CC17), hex(0x3DDB86), hex(0x1A9334), hex(0x00D4BB),
+	hex(0x2C99A8), hex(0x00C2FF), hex(0x344593), hex(0x6473FF), hex(0x0018EC),
+	hex(0x8438FF), hex(0x520085), hex(0xCB38FF), hex(0xFF95C8), hex(0xFF37C7),
+}
+
+// hex 0xRRGGBB 转为不透明的颜色
+func hex(v uint32) color.RGBA {
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}
+}
+
+// textColor 在背景色上清晰可见的文字颜色，浅色背景使用黑色，深色背景使用白色
+func textColor(bg color.RGBA) color.RGBA {
+	// ITU-R BT.601 亮度
+	luma := 0.299*float64(bg.R) + 0.587*float64(bg.G) + 0.114*float64(bg.B)
+	if luma > 160 {
+		return color.RGBA{A: 255}
+	}
+	return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+}
@@ -0,0 +1,109 @@
+package vision
+
+import (
+	"fmt"
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"image"
+)
+
+// PredictBatch 分块批量推理，返回的结果与 imgs 一一对应
+//
+// 模型的批大小固定为 N (N > 0) 时每次推理 N 张图片，最后一块不足 N 张时用最后一张图片补齐，补齐的结果会被丢弃；
+// 批大小为动态维度时每次最多推理 maxBatch 张图片
+//
+// # Params:
+//
+//	imgs: 原图
+//	batchSize: 模型输入固定的批大小，<= 0 表示动态维度
+//	maxBatch: 动态批大小时每次推理的最大图片数量，<= 0 表示不限制
+//	run: 将传入的图片打包为一个输入执行推理，返回的结果与传入的图片一一对应
+func PredictBatch[T any](imgs []image.Image, batchSize, maxBatch int, run func(batch []image.Image) ([][]T, error)) ([][]T, error) {
+	if len(imgs) == 0 {
+		return [][]T{}, nil
+	}
+
+	chunk := len(imgs)
+	if batchSize > 0 {
+		chunk = batchSize
+	} else if maxBatch > 0 {
+		chunk = min(maxBatch, len(imgs))
+	}
+
+	results := make([][]T, 0, len(imgs))
+	for start := 0; start < len(imgs); start += chunk {
+		batch := imgs[start:min(start+chunk, len(imgs))]
+		n := len(batch)
+		if batchSize > 0 && n < batchSize {
+			padded := make([]image.Image, batchSize)
+			copy(padded, batch)
+			for i := n; i < batchSize; i++ {
+				padded[i] = batch[n-1]
+			}
+			batch = padded
+		}
+
+		res, err := run(batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(res) != len(batch) {
+			return nil, fmt.Errorf("推理结果数量 %d 与图片数量 %d 不一致", len(res), len(batch))
+		}
+		results = append(results, res[:n]...)
+	}
+	return results, nil
+}
+
+// PreprocessBatch 预处理，将 imgs 等比缩放后打包为 [N, 3, S, S] 的输入
+//
+// # Params:
+//
+//	imgs: 原图
+//	inputSize: 模型输入尺寸 S
+//	mode: 放置方式
+//	padValue: 未被图片覆盖区域的填充值 (0-255)
+func PreprocessBatch(imgs []image.Image, inputSize int, mode LetterboxMode, padValue uint8) (*ort.Value, []LetterboxParams, error) {
+	planeSize := 3 * inputSize * inputSize
+
+	// 准备 Tensor 数据 (CHW + Normalize 0-1)，未被图片覆盖的区域使用 padValue 填充
+	data := make([]float32, len(imgs)*planeSize)
+	if padValue > 0 {
+		v := float32(padValue) / 255.0
+		for i := range data {
+			data[i] = v
+		}
+	}
+
+	params := make([]LetterboxParams, len(imgs))
+	for i, img := range imgs {
+		params[i] = Letterbox(data[i*planeSize:(i+1)*planeSize], img, inputSize, mode)
+	}
+
+	tensor, err := ort.NewTensor([]int64{int64(len(imgs)), 3, int64(inputSize), int64(inputSize)}, data)
+	return tensor, params, err
+}
+
+// PreprocessCenterCrop 分类模型的预处理，将 imgs 短边缩放后居中裁剪，打包为 [N, 3, S, S] 的输入
+//
+// # Params:
+//
+//	imgs: 原图
+//	inputSize: 模型输入尺寸 S
+func PreprocessCenterCrop(imgs []image.Image, inputSize int) (*ort.Value, error) {
+	planeSize := 3 * inputSize * inputSize
+	data := make([]float32, len(imgs)*planeSize)
+	for i, img := range imgs {
+		CenterCrop(data[i*planeSize:(i+1)*planeSize], img, inputSize)
+	}
+	return ort.NewTensor([]int64{int64(len(imgs)), 3, int64(inputSize), int64(inputSize)}, data)
+}
+
+// BatchItem 取出批量输出中第 i 张图片的数据，返回的形状中批大小为 1
+func BatchItem(data []float32, shape []int64, i int) ([]float32, []int64) {
+	size := 1
+	for _, d := range shape[1:] {
+		size *= int(d)
+	}
+	itemShape := append([]int64{1}, shape[1:]...)
+	return data[i*size : (i+1)*size], itemShape
+}
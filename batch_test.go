@@ -0,0 +1,88 @@
+package vision
+
+import (
+	"errors"
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestPredictBatch(t *testing.T) {
+	imgs := []image.Image{
+		image.NewRGBA(image.Rect(0, 0, 10, 10)),
+		image.NewRGBA(image.Rect(0, 0, 20, 20)),
+		image.NewRGBA(image.Rect(0, 0, 30, 30)),
+	}
+	var calls []int
+	run := func(batch []image.Image) ([][]int, error) {
+		calls = append(calls, len(batch))
+		results := make([][]int, len(batch))
+		for i, img := range batch {
+			results[i] = []int{img.Bounds().Dx()}
+		}
+		return results, nil
+	}
+	want := [][]int{{10}, {20}, {30}}
+
+	tests := []struct {
+		name      string
+		batchSize int
+		maxBatch  int
+		calls     []int
+	}{
+		{"动态批大小一次推理", 0, 0, []int{3}},
+		{"动态批大小按 MaxBatch 分块", 0, 2, []int{2, 1}},
+		{"MaxBatch 大于图片数量", 0, 8, []int{3}},
+		{"固定批大小为 1", 1, 0, []int{1, 1, 1}},
+		{"固定批大小补齐最后一块", 2, 0, []int{2, 2}},
+		{"固定批大小忽略 MaxBatch", 4, 1, []int{4}},
+	}
+	for _, tt := range tests {
+		calls = nil
+		results, err := PredictBatch(imgs, tt.batchSize, tt.maxBatch, run)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(calls, tt.calls) {
+			t.Errorf("%s: 每次推理的图片数量应为 %v，实际 %v", tt.name, tt.calls, calls)
+		}
+		if !reflect.DeepEqual(results, want) {
+			t.Errorf("%s: 结果应为 %v，实际 %v", tt.name, want, results)
+		}
+	}
+
+	if results, err := PredictBatch(nil, 0, 0, run); err != nil || len(results) != 0 {
+		t.Errorf("没有图片时应返回空结果，实际 %v %v", results, err)
+	}
+
+	// 错误直接返回
+	_, err := PredictBatch(imgs, 1, 0, func([]image.Image) ([][]int, error) {
+		return nil, errors.New("推理失败")
+	})
+	if err == nil {
+		t.Error("推理失败时应返回错误")
+	}
+	_, err = PredictBatch(imgs, 0, 0, func([]image.Image) ([][]int, error) {
+		return [][]int{{1}}, nil
+	})
+	if err == nil {
+		t.Error("结果数量与图片数量不一致时应返回错误")
+	}
+}
+
+func TestBatchItem(t *testing.T) {
+	// [2, 3, 2] 的输出，两张图片各 6 个数值
+	data := []float32{0, 1, 2, 3, 4, 5, 10, 11, 12, 13, 14, 15}
+	shape := []int64{2, 3, 2}
+
+	item, itemShape := BatchItem(data, shape, 1)
+	if len(item) != 6 || item[0] != 10 || item[5] != 15 {
+		t.Fatalf("第 2 张图片的数据错误: %v", item)
+	}
+	if len(itemShape) != 3 || itemShape[0] != 1 || itemShape[1] != 3 || itemShape[2] != 2 {
+		t.Fatalf("单张图片的形状应为 [1, 3, 2]，实际 %v", itemShape)
+	}
+	if shape[0] != 2 {
+		t.Fatalf("不应修改原始形状，实际 %v", shape)
+	}
+}
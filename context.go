@@ -0,0 +1,40 @@
+package vision
+
+import (
+	"context"
+	"fmt"
+)
+
+// 推理阶段
+const (
+	StagePreprocess  = "preprocess"  // 预处理
+	StageInference   = "inference"   // 模型推理
+	StagePostprocess = "postprocess" // 后处理
+)
+
+// CanceledError 推理因 ctx 结束而中止
+type CanceledError struct {
+	Stage string // 中止时所处的阶段
+	Err   error  // ctx.Err()，context.Canceled 或 context.DeadlineExceeded
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("推理在 %s 阶段中止: %v", e.Stage, e.Err)
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// CheckContext 检查 ctx 是否已结束，结束时返回 *CanceledError
+//
+// # Params:
+//
+//	ctx: 上下文
+//	stage: 即将进入的阶段
+func CheckContext(ctx context.Context, stage string) error {
+	if err := ctx.Err(); err != nil {
+		return &CanceledError{Stage: stage, Err: err}
+	}
+	return nil
+}
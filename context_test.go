@@ -0,0 +1,28 @@
+package vision
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckContext(t *testing.T) {
+	if err := CheckContext(context.Background(), StagePreprocess); err != nil {
+		t.Fatalf("未结束的 ctx 不应返回错误，实际 %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := CheckContext(ctx, StagePostprocess)
+
+	var ce *CanceledError
+	if !errors.As(err, &ce) {
+		t.Fatalf("应返回 *CanceledError，实际 %T", err)
+	}
+	if ce.Stage != StagePostprocess {
+		t.Fatalf("阶段应为 %s，实际 %s", StagePostprocess, ce.Stage)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal("应可通过 errors.Is 判断 context.Canceled")
+	}
+}
@@ -0,0 +1,297 @@
+package vision
+
+import (
+	"image"
+	"math"
+)
+
+// Polygon Mask 连通域的轮廓
+//
+// 顶点位于像素的角点上，像素 (x, y) 占据 [x, x+1) x [y, y+1) 的区域，因此多边形的面积与像素数一致。
+// 在图片坐标系 (y 轴向下) 中 Outer 为顺时针，Holes 为逆时针
+type Polygon struct {
+	Outer []image.Point   // 外轮廓
+	Holes [][]image.Point // 内部孔洞的轮廓
+}
+
+// Area 多边形面积，外轮廓面积减去孔洞面积
+func (p Polygon) Area() float64 {
+	area := math.Abs(ringArea(p.Outer))
+	for _, h := range p.Holes {
+		area -= math.Abs(ringArea(h))
+	}
+	return area
+}
+
+// ContourOptions 轮廓提取参数
+type ContourOptions struct {
+	Tolerance   float64 // Douglas-Peucker 简化的容差 (像素)，<= 0 时不简化，只保留拐点
+	LargestOnly bool    // 只返回面积最大的连通域
+}
+
+// MaskPolygons 提取 Mask 中所有连通域的轮廓
+//
+// 前景 (像素值 > 127) 按 8 邻域连通，每个连通域返回一个多边形，按连通域最上方 (其次最左侧) 像素的顺序排列，
+// 坐标与 mask 的 Bounds 一致
+//
+// # Params:
+//
+//	mask: 二值 Mask，例如 SegResult.Mask
+//	opts: 轮廓提取参数
+func MaskPolygons(mask *image.Gray, opts ContourOptions) []Polygon {
+	if mask == nil || mask.Rect.Empty() {
+		return nil
+	}
+	w, h := mask.Rect.Dx(), mask.Rect.Dy()
+	fg := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < w && y < h && mask.Pix[y*mask.Stride+x] > 127
+	}
+
+	// 连通域标记
+	labels := make([]int32, w*h)
+	numLabels := int32(0)
+	var stack []int
+	for i := range labels {
+		x, y := i%w, i/w
+		if labels[i] != 0 || !fg(x, y) {
+			continue
+		}
+		numLabels++
+		labels[i] = numLabels
+		stack = append(stack[:0], i)
+		for len(stack) > 0 {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			jx, jy := j%w, j/w
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := jx+dx, jy+dy
+					if fg(nx, ny) && labels[ny*w+nx] == 0 {
+						labels[ny*w+nx] = numLabels
+						stack = append(stack, ny*w+nx)
+					}
+				}
+			}
+		}
+	}
+	if numLabels == 0 {
+		return nil
+	}
+
+	// 边界边：前景在边的右侧，out[v] 为顶点 v 出发的边的方向集合
+	vw := w + 1
+	out := make([]uint8, vw*(h+1))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !fg(x, y) {
+				continue
+			}
+			if !fg(x, y-1) {
+				out[y*vw+x] |= 1 << dirRight
+			}
+			if !fg(x+1, y) {
+				out[y*vw+x+1] |= 1 << dirDown
+			}
+			if !fg(x, y+1) {
+				out[(y+1)*vw+x+1] |= 1 << dirLeft
+			}
+			if !fg(x-1, y) {
+				out[(y+1)*vw+x] |= 1 << dirUp
+			}
+		}
+	}
+
+	// 逐个追踪闭合轮廓，外轮廓面积为正，孔洞面积为负
+	polys := make([]Polygon, numLabels)
+	areas := make([]float64, numLabels)
+	for v := range out {
+		for out[v] != 0 {
+			d := dirRight
+			for out[v]&(1<<d) == 0 {
+				d++
+			}
+			x, y := v%vw, v/vw
+			// 边右侧的像素所属的连通域
+			var px, py int
+			switch d {
+			case dirRight:
+				px, py = x, y
+			case dirDown:
+				px, py = x-1, y
+			case dirLeft:
+				px, py = x-1, y-1
+			case dirUp:
+				px, py = x, y-1
+			}
+			label := labels[py*w+px] - 1
+
+			ring := traceRing(out, vw, v, d)
+			area := ringArea(ring)
+			offsetRing(ring, mask.Rect.Min)
+			if area > 0 {
+				polys[label].Outer = ring
+			} else {
+				polys[label].Holes = append(polys[label].Holes, ring)
+			}
+			areas[label] += area
+		}
+	}
+
+	if opts.LargestOnly {
+		best := 0
+		for i := range areas {
+			if areas[i] > areas[best] {
+				best = i
+			}
+		}
+		polys = polys[best : best+1]
+	}
+
+	if opts.Tolerance > 0 {
+		for i := range polys {
+			polys[i] = simplifyPolygon(polys[i], opts.Tolerance)
+		}
+	}
+	return polys
+}
+
+// 轮廓边的方向，顺时针排列
+const (
+	dirRight = iota
+	dirDown
+	dirLeft
+	dirUp
+)
+
+var (
+	dirDX = [4]int{1, 0, -1, 0}
+	dirDY = [4]int{0, 1, 0, -1}
+)
+
+// traceRing 从顶点 start 沿方向 dir 追踪一条闭合轮廓，返回拐点，经过的边从 out 中移除
+//
+// 两个前景像素只在对角相接时，顶点上有两条出边，此时优先左转，使对角相接的像素属于同一个轮廓 (8 邻域)
+func traceRing(out []uint8, vw, start, dir int) []image.Point {
+	var ring []image.Point
+	v, d := start, dir
+	for {
+		out[v] &^= 1 << d
+		next := v + dirDY[d]*vw + dirDX[d]
+
+		nd := -1
+		for _, c := range [3]int{(d + 3) % 4, d, (d + 1) % 4} {
+			if out[next]&(1<<c) != 0 || (next == start && c == dir) {
+				nd = c
+				break
+			}
+		}
+		if nd != d {
+			ring = append(ring, image.Pt(next%vw, next/vw))
+		}
+		if next == start && nd == dir {
+			return ring
+		}
+		v, d = next, nd
+	}
+}
+
+// ringArea 闭合轮廓的有向面积，在图片坐标系中顺时针为正
+func ringArea(ring []image.Point) float64 {
+	var sum int
+	for i, p := range ring {
+		q := ring[(i+1)%len(ring)]
+		sum += p.X*q.Y - q.X*p.Y
+	}
+	return float64(sum) / 2
+}
+
+// offsetRing 平移轮廓
+func offsetRing(ring []image.Point, offset image.Point) {
+	for i := range ring {
+		ring[i] = ring[i].Add(offset)
+	}
+}
+
+// simplifyPolygon 使用 Douglas-Peucker 算法简化多边形，简化后不足 3 个顶点的外轮廓保持不变，孔洞被移除
+func simplifyPolygon(p Polygon, tolerance float64) Polygon {
+	if s := SimplifyRing(p.Outer, tolerance); len(s) >= 3 {
+		p.Outer = s
+	}
+	holes := p.Holes[:0]
+	for _, h := range p.Holes {
+		if s := SimplifyRing(h, tolerance); len(s) >= 3 {
+			holes = append(holes, s)
+		}
+	}
+	if len(holes) == 0 {
+		holes = nil
+	}
+	p.Holes = holes
+	return p
+}
+
+// SimplifyRing 使用 Douglas-Peucker 算法简化闭合轮廓
+//
+// 以第一个顶点和距离它最远的顶点把轮廓分为两段折线，分别简化后拼接
+//
+// # Params:
+//
+//	ring: 闭合轮廓，首尾顶点不重复
+//	tolerance: 容差，顶点到简化后线段的距离不超过该值
+func SimplifyRing(ring []image.Point, tolerance float64) []image.Point {
+	if len(ring) < 3 || tolerance <= 0 {
+		return ring
+	}
+	far, farDist := 0, -1
+	for i, p := range ring {
+		dx, dy := p.X-ring[0].X, p.Y-ring[0].Y
+		if d := dx*dx + dy*dy; d > farDist {
+			far, farDist = i, d
+		}
+	}
+
+	// 两段折线：ring[0..far] 与 ring[far..n-1, 0]
+	closed := append(append([]image.Point{}, ring...), ring[0])
+	keep := make([]bool, len(closed))
+	keep[0], keep[far], keep[len(closed)-1] = true, true, true
+	douglasPeucker(closed, 0, far, tolerance, keep)
+	douglasPeucker(closed, far, len(closed)-1, tolerance, keep)
+
+	var result []image.Point
+	for i := 0; i < len(ring); i++ {
+		if keep[i] {
+			result = append(result, ring[i])
+		}
+	}
+	return result
+}
+
+// douglasPeucker 标记折线 pts[first..last] 中需要保留的顶点
+func douglasPeucker(pts []image.Point, first, last int, tolerance float64, keep []bool) {
+	if last-first < 2 {
+		return
+	}
+	a, b := pts[first], pts[last]
+	abx, aby := float64(b.X-a.X), float64(b.Y-a.Y)
+	length := math.Hypot(abx, aby)
+
+	index, maxDist := -1, tolerance
+	for i := first + 1; i < last; i++ {
+		apx, apy := float64(pts[i].X-a.X), float64(pts[i].Y-a.Y)
+		var dist float64
+		if length == 0 {
+			dist = math.Hypot(apx, apy)
+		} else {
+			dist = math.Abs(abx*apy-aby*apx) / length
+		}
+		if dist > maxDist {
+			index, maxDist = i, dist
+		}
+	}
+	if index < 0 {
+		return
+	}
+	keep[index] = true
+	douglasPeucker(pts, first, index, tolerance, keep)
+	douglasPeucker(pts, index, last, tolerance, keep)
+}
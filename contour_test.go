@@ -0,0 +1,114 @@
+package vision
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+// fillMask 在 Mask 上填充矩形区域
+func fillMask(m *image.Gray, r image.Rectangle) {
+	r = r.Intersect(m.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			m.Pix[m.PixOffset(x, y)] = 255
+		}
+	}
+}
+
+func TestMaskPolygons_Hole(t *testing.T) {
+	// 6x6 的方块，中间挖去 2x2 的孔洞
+	m := image.NewGray(image.Rect(0, 0, 10, 10))
+	fillMask(m, image.Rect(2, 2, 8, 8))
+	for y := 4; y < 6; y++ {
+		for x := 4; x < 6; x++ {
+			m.Pix[m.PixOffset(x, y)] = 0
+		}
+	}
+
+	polys := MaskPolygons(m, ContourOptions{})
+	if len(polys) != 1 {
+		t.Fatalf("应有 1 个连通域，实际 %d", len(polys))
+	}
+	p := polys[0]
+	wantOuter := []image.Point{{8, 2}, {8, 8}, {2, 8}, {2, 2}}
+	if !reflect.DeepEqual(p.Outer, wantOuter) {
+		t.Errorf("外轮廓 %v，预期 %v", p.Outer, wantOuter)
+	}
+	if len(p.Holes) != 1 || len(p.Holes[0]) != 4 {
+		t.Fatalf("应有 1 个 4 顶点的孔洞，实际 %v", p.Holes)
+	}
+	if ringArea(p.Holes[0]) != -4 {
+		t.Errorf("孔洞应为逆时针、面积为 4，实际有向面积 %v", ringArea(p.Holes[0]))
+	}
+	if p.Area() != 32 {
+		t.Errorf("面积应为 32，实际 %v", p.Area())
+	}
+}
+
+func TestMaskPolygons_Components(t *testing.T) {
+	m := image.NewGray(image.Rect(0, 0, 20, 10))
+	fillMask(m, image.Rect(0, 0, 2, 2))
+	fillMask(m, image.Rect(10, 2, 15, 7))
+	// 与第二个方块对角相接的像素属于同一个连通域
+	fillMask(m, image.Rect(15, 7, 16, 8))
+
+	polys := MaskPolygons(m, ContourOptions{})
+	if len(polys) != 2 {
+		t.Fatalf("应有 2 个连通域，实际 %d", len(polys))
+	}
+	if polys[0].Area() != 4 || polys[1].Area() != 26 {
+		t.Errorf("面积应为 4 和 26，实际 %v 和 %v", polys[0].Area(), polys[1].Area())
+	}
+
+	largest := MaskPolygons(m, ContourOptions{LargestOnly: true})
+	if len(largest) != 1 || largest[0].Area() != 26 {
+		t.Fatalf("应只返回面积最大的连通域，实际 %v", largest)
+	}
+}
+
+func TestMaskPolygons_Bounds(t *testing.T) {
+	// 只保存检测框区域的 Mask，坐标应为整幅图片的坐标
+	m := image.NewGray(image.Rect(100, 50, 110, 60))
+	fillMask(m, image.Rect(102, 52, 105, 55))
+
+	r := SegResult{Mask: m, MaskSize: image.Pt(200, 100)}
+	polys := r.Polygons(ContourOptions{})
+	want := []image.Point{{105, 52}, {105, 55}, {102, 55}, {102, 52}}
+	if len(polys) != 1 || !reflect.DeepEqual(polys[0].Outer, want) {
+		t.Fatalf("轮廓 %v，预期 %v", polys, want)
+	}
+
+	if polys := MaskPolygons(image.NewGray(image.Rect(0, 0, 4, 4)), ContourOptions{}); polys != nil {
+		t.Errorf("空 Mask 不应有轮廓，实际 %v", polys)
+	}
+}
+
+func TestMaskPolygons_Simplify(t *testing.T) {
+	// 阶梯状的直角三角形
+	m := image.NewGray(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		fillMask(m, image.Rect(0, y, y+1, y+1))
+	}
+
+	raw := MaskPolygons(m, ContourOptions{})[0]
+	simple := MaskPolygons(m, ContourOptions{Tolerance: 1.5})[0]
+	if len(simple.Outer) >= len(raw.Outer) || len(simple.Outer) < 3 {
+		t.Fatalf("简化后顶点数 %d，简化前 %d", len(simple.Outer), len(raw.Outer))
+	}
+	if area := simple.Area(); area < 180 || area > 240 {
+		t.Errorf("简化后面积 %v 与原面积 %v 相差过大", area, raw.Area())
+	}
+}
+
+func TestSimplifyRing(t *testing.T) {
+	// 共线的顶点被移除
+	ring := []image.Point{{0, 0}, {5, 0}, {10, 0}, {10, 10}, {5, 10}, {0, 10}}
+	want := []image.Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	if got := SimplifyRing(ring, 0.5); !reflect.DeepEqual(got, want) {
+		t.Errorf("简化结果 %v，预期 %v", got, want)
+	}
+	if got := SimplifyRing(ring, 0); !reflect.DeepEqual(got, ring) {
+		t.Errorf("容差为 0 时不应简化，实际 %v", got)
+	}
+}
@@ -0,0 +1,61 @@
+package vision
+
+import (
+	"context"
+	"image"
+)
+
+// Detector 目标检测引擎，yolov11.DetEngine 与 yolo26.DetEngine 均实现了该接口
+type Detector interface {
+	Predict(img image.Image) ([]DetResult, error)
+	PredictContext(ctx context.Context, img image.Image) ([]DetResult, error)
+	PredictBatch(imgs []image.Image) ([][]DetResult, error)
+	PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]DetResult, error)
+	Destroy()
+}
+
+// Segmenter 实例分割引擎，yolov11.SegEngine 与 yolo26.SegEngine 均实现了该接口
+type Segmenter interface {
+	Predict(img image.Image) ([]SegResult, error)
+	PredictContext(ctx context.Context, img image.Image) ([]SegResult, error)
+	PredictBatch(imgs []image.Image) ([][]SegResult, error)
+	PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]SegResult, error)
+	Destroy()
+}
+
+// PoseEstimator 姿态估计引擎，yolov11.PoseEngine 与 yolo26.PoseEngine 均实现了该接口
+type PoseEstimator interface {
+	Predict(img image.Image) ([]PoseResult, error)
+	PredictContext(ctx context.Context, img image.Image) ([]PoseResult, error)
+	PredictBatch(imgs []image.Image) ([][]PoseResult, error)
+	PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]PoseResult, error)
+	Destroy()
+}
+
+// OBBDetector 旋转目标检测引擎，yolov11.OBBEngine 与 yolo26.OBBEngine 均实现了该接口
+type OBBDetector interface {
+	Predict(img image.Image) ([]OBBResult, error)
+	PredictContext(ctx context.Context, img image.Image) ([]OBBResult, error)
+	PredictBatch(imgs []image.Image) ([][]OBBResult, error)
+	PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]OBBResult, error)
+	Destroy()
+}
+
+// Classifier 图像分类引擎，yolov11.ClsEngine 与 yolo26.ClsEngine 均实现了该接口
+type Classifier interface {
+	Predict(img image.Image, topK int) ([]ClassResult, error)
+	PredictContext(ctx context.Context, img image.Image, topK int) ([]ClassResult, error)
+	PredictBatch(imgs []image.Image, topK int) ([][]ClassResult, error)
+	PredictBatchContext(ctx context.Context, imgs []image.Image, topK int) ([][]ClassResult, error)
+	Destroy()
+}
+
+// Namer 提供类别名称表的引擎，yolov11、yolo26 的各引擎及 sahi 包的包装器均实现了该接口
+type Namer interface {
+	Names() []string
+}
+
+// MaskResolver 提供 Mask 分辨率的分割引擎，yolov11、yolo26 的 SegEngine 及 sahi.Segmenter 均实现了该接口
+type MaskResolver interface {
+	MaskResolution() MaskResolution
+}
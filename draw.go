@@ -8,14 +8,30 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"io/fs"
 	"os"
+	"strings"
 )
 
 // TextDrawer 文本绘制工具
 type TextDrawer struct {
-	font     *opentype.Font
+	fonts    []*opentype.Font // 主字体及回退字体，按顺序查找字形
 	face     font.Face
 	fontSize float64
+
+	lineSpacing  float64     // 行距倍数
+	outlineWidth int         // 描边宽度，0 表示不描边
+	outlineColor color.Color // 描边颜色
+}
+
+// TextMetrics 文本尺寸，多行文本以 \n 分隔
+type TextMetrics struct {
+	Width      int // 最宽一行的前进宽度 (advance)
+	Height     int // 第一行基线以上到最后一行基线以下的总高度
+	Ascent     int // 基线以上的高度
+	Descent    int // 基线以下的高度
+	LineHeight int // 相邻两行基线之间的距离
+	Lines      int // 行数
 }
 
 // NewTextDrawer 创建文本绘制工具
@@ -28,19 +44,67 @@ func NewTextDrawer(fontPath string) (*TextDrawer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("打开字体文件失败：%w", err)
 	}
+	return NewTextDrawerFromBytes(fontBytes)
+}
+
+// NewTextDrawerFS 从文件系统 (例如 embed.FS) 中加载字体，创建文本绘制工具
+//
+// # Params:
+//
+//	fsys: 文件系统
+//	name: 字体在 fsys 中的路径
+func NewTextDrawerFS(fsys fs.FS, name string) (*TextDrawer, error) {
+	fontBytes, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("打开字体文件失败：%w", err)
+	}
+	return NewTextDrawerFromBytes(fontBytes)
+}
 
+// NewTextDrawerFromBytes 从字体数据创建文本绘制工具
+//
+// # Params:
+//
+//	fontBytes: TTF/OTF 字体数据
+func NewTextDrawerFromBytes(fontBytes []byte) (*TextDrawer, error) {
 	ttFont, err := opentype.Parse(fontBytes)
 	if err != nil {
 		return nil, fmt.Errorf("解析字体文件失败：%w", err)
 	}
 
-	d := &TextDrawer{font: ttFont}
+	d := &TextDrawer{fonts: []*opentype.Font{ttFont}, lineSpacing: 1.2}
 	if err := d.SetSize(12); err != nil {
 		return nil, err
 	}
 	return d, nil
 }
 
+// AddFallback 添加回退字体，已有字体中缺少的字形依次从回退字体中查找
+//
+// 例如主字体只包含拉丁字母时，可以添加 fonts.Unifont 显示中文
+//
+// # Params:
+//
+//	fontBytes: TTF/OTF 字体数据
+func (d *TextDrawer) AddFallback(fontBytes []byte) error {
+	ttFont, err := opentype.Parse(fontBytes)
+	if err != nil {
+		return fmt.Errorf("解析字体文件失败：%w", err)
+	}
+
+	// 先创建新的 Face，成功后再更新字体列表，失败时保持原状态
+	fonts := append(d.fonts[:len(d.fonts):len(d.fonts)], ttFont)
+	face, err := newFace(fonts, d.fontSize)
+	if err != nil {
+		return err
+	}
+	if d.face != nil {
+		d.face.Close()
+	}
+	d.fonts, d.face = fonts, face
+	return nil
+}
+
 // SetSize 动态调整字体大小
 //
 // # Params:
@@ -51,23 +115,91 @@ func (d *TextDrawer) SetSize(fontSize float64) error {
 		return nil
 	}
 
+	face, err := newFace(d.fonts, fontSize)
+	if err != nil {
+		return err
+	}
+
 	// 释放旧 Face 内存
 	if d.face != nil {
 		d.face.Close()
 	}
+	d.face = face
+	d.fontSize = fontSize
+	return nil
+}
 
-	nf, err := opentype.NewFace(d.font, &opentype.FaceOptions{
-		Size:    fontSize,
-		DPI:     72,
-		Hinting: font.HintingFull,
-	})
-	if err != nil {
-		return err
+// newFace 按字体顺序创建指定字号的 Face，多个字体时缺少的字形依次从后面的字体中查找
+func newFace(fonts []*opentype.Font, fontSize float64) (font.Face, error) {
+	faces := make([]font.Face, 0, len(fonts))
+	for _, f := range fonts {
+		nf, err := opentype.NewFace(f, &opentype.FaceOptions{
+			Size:    fontSize,
+			DPI:     72,
+			Hinting: font.HintingFull,
+		})
+		if err != nil {
+			for _, face := range faces {
+				face.Close()
+			}
+			return nil, err
+		}
+		faces = append(faces, nf)
 	}
 
-	d.face = nf
-	d.fontSize = fontSize
-	return nil
+	if len(faces) == 1 {
+		return faces[0], nil
+	}
+	return &fallbackFace{fonts: fonts, faces: faces}, nil
+}
+
+// Face 当前字号的字体，可用于 annotator.Config 等接受 font.Face 的场景
+func (d *TextDrawer) Face() font.Face {
+	return d.face
+}
+
+// SetLineSpacing 设置多行文本的行距
+//
+// # Params:
+//
+//	spacing: 行距倍数，相邻两行基线的距离为 (ascent + descent) * spacing，默认 1.2
+func (d *TextDrawer) SetLineSpacing(spacing float64) {
+	if spacing > 0 {
+		d.lineSpacing = spacing
+	}
+}
+
+// SetOutline 设置文字描边，在背景复杂的画面 (例如视频帧) 上提高可读性
+//
+// # Params:
+//
+//	width: 描边宽度 (像素)，0 表示不描边
+//	c: 描边颜色
+func (d *TextDrawer) SetOutline(width int, c color.Color) {
+	d.outlineWidth = max(width, 0)
+	d.outlineColor = c
+}
+
+// MeasureText 测量文本尺寸，不包含描边
+//
+// # Params:
+//
+//	text: 文本，多行以 \n 分隔
+func (d *TextDrawer) MeasureText(text string) TextMetrics {
+	metrics := d.face.Metrics()
+	m := TextMetrics{
+		Ascent:  metrics.Ascent.Ceil(),
+		Descent: metrics.Descent.Ceil(),
+	}
+	m.LineHeight = d.lineHeight(m.Ascent, m.Descent)
+
+	lines := strings.Split(text, "\n")
+	m.Lines = len(lines)
+	for _, line := range lines {
+		m.Width = max(m.Width, font.MeasureString(d.face, line).Ceil())
+	}
+	m.Height = m.Ascent + m.Descent + (m.Lines-1)*m.LineHeight
+	return m
 }
 
 // DrawText 绘制文本
@@ -75,22 +207,69 @@ func (d *TextDrawer) SetSize(fontSize float64) error {
 // # Params:
 //
 //	img: 被绘制的图像
-//	text: 绘制的文本
-//	x, y: 绘制的坐标
+//	text: 绘制的文本，多行以 \n 分隔
+//	x, y: 第一行基线的起点
 //	c: 绘制的颜色
 func (d *TextDrawer) DrawText(img draw.Image, text string, x, y int, c color.Color) {
-	point := fixed.Point26_6{
-		X: fixed.I(x),
-		Y: fixed.I(y),
+	metrics := d.face.Metrics()
+	lineHeight := d.lineHeight(metrics.Ascent.Ceil(), metrics.Descent.Ceil())
+
+	for i, line := range strings.Split(text, "\n") {
+		baseline := y + i*lineHeight
+
+		// 描边：在半径范围内的各个偏移位置绘制描边颜色
+		if w := d.outlineWidth; w > 0 && d.outlineColor != nil {
+			for dy := -w; dy <= w; dy++ {
+				for dx := -w; dx <= w; dx++ {
+					if (dx != 0 || dy != 0) && dx*dx+dy*dy <= w*w {
+						d.drawLine(img, line, x+dx, baseline+dy, d.outlineColor)
+					}
+				}
+			}
+		}
+		d.drawLine(img, line, x, baseline, c)
 	}
+}
 
-	d1 := &font.Drawer{
-		Dst:  img,
-		Src:  image.NewUniform(c), // 文字颜色源
-		Face: d.face,
-		Dot:  point, // 开始绘制的点
+// DrawTextBox 绘制带背景的文本，文本框超出图片时移动到图片范围内
+//
+// # Params:
+//
+//	img: 被绘制的图像
+//	text: 绘制的文本，多行以 \n 分隔
+//	x, y: 文本框左上角的坐标
+//	c: 文字颜色
+//	bg: 背景颜色，nil 时不填充背景
+//	padding: 文字与文本框边缘的距离 (像素)
+//
+// # Returns:
+//
+//	实际绘制的文本框
+func (d *TextDrawer) DrawTextBox(img draw.Image, text string, x, y int, c, bg color.Color, padding int) image.Rectangle {
+	m := d.MeasureText(text)
+	inset := padding + d.outlineWidth
+	box := image.Rect(x, y, x+m.Width+inset*2, y+m.Height+inset*2)
+
+	// 限制在图片范围内，文本框大于图片时与左上角对齐
+	bounds := img.Bounds()
+	if box.Max.X > bounds.Max.X {
+		box = box.Sub(image.Pt(box.Max.X-bounds.Max.X, 0))
 	}
-	d1.DrawString(text)
+	if box.Max.Y > bounds.Max.Y {
+		box = box.Sub(image.Pt(0, box.Max.Y-bounds.Max.Y))
+	}
+	if box.Min.X < bounds.Min.X {
+		box = box.Add(image.Pt(bounds.Min.X-box.Min.X, 0))
+	}
+	if box.Min.Y < bounds.Min.Y {
+		box = box.Add(image.Pt(0, bounds.Min.Y-box.Min.Y))
+	}
+
+	if bg != nil {
+		draw.Draw(img, box.Intersect(bounds), image.NewUniform(bg), image.Point{}, draw.Over)
+	}
+	d.DrawText(img, text, box.Min.X+inset, box.Min.Y+inset+m.Ascent, c)
+	return box
 }
 
 // Close 释放资源
@@ -99,3 +278,23 @@ func (d *TextDrawer) Close() {
 		d.face.Close()
 	}
 }
+
+// lineHeight 相邻两行基线之间的距离
+func (d *TextDrawer) lineHeight(ascent, descent int) int {
+	spacing := d.lineSpacing
+	if spacing <= 0 {
+		spacing = 1.2
+	}
+	return int(float64(ascent+descent)*spacing + 0.5)
+}
+
+// drawLine 绘制单行文本
+func (d *TextDrawer) drawLine(img draw.Image, text string, x, y int, c color.Color) {
+	d1 := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c), // 文字颜色源
+		Face: d.face,
+		Dot:  fixed.P(x, y), // 开始绘制的点
+	}
+	d1.DrawString(text)
+}
@@ -2,9 +2,14 @@ package vision
 
 import (
 	"github.com/up-zero/gotool/imageutil"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"image"
 	"image/color"
 	"image/draw"
+	"os"
 	"testing"
+	"testing/fstest"
 )
 
 func TestDrawer_DrawText(t *testing.T) {
@@ -27,3 +32,158 @@ func TestDrawer_DrawText(t *testing.T) {
 	d.DrawText(srcImage, "Hello World", 10, 10, color.Black)
 	imageutil.Save("draw.png", srcImage, 100)
 }
+
+// goDrawer 使用 Go 字体的文本绘制工具，不依赖字体文件
+func goDrawer(t *testing.T) *TextDrawer {
+	d, err := NewTextDrawerFromBytes(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetSize(20); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestTextDrawer_MeasureText(t *testing.T) {
+	d := goDrawer(t)
+	defer d.Close()
+
+	one := d.MeasureText("Hello")
+	if one.Width <= 0 || one.Ascent <= 0 || one.Descent <= 0 || one.Lines != 1 {
+		t.Fatalf("单行文本尺寸 %+v", one)
+	}
+	if one.Height != one.Ascent+one.Descent {
+		t.Errorf("单行高度应为 ascent + descent，实际 %+v", one)
+	}
+	if wide := d.MeasureText("Hello World"); wide.Width <= one.Width {
+		t.Errorf("更长的文本应更宽: %d <= %d", wide.Width, one.Width)
+	}
+
+	// 多行文本的宽度为最宽一行，高度随行距增加
+	multi := d.MeasureText("Hi\nHello")
+	if multi.Lines != 2 || multi.Width != one.Width || multi.Height != one.Height+one.LineHeight {
+		t.Errorf("多行文本尺寸 %+v", multi)
+	}
+	d.SetLineSpacing(2)
+	if spaced := d.MeasureText("Hi\nHello"); spaced.LineHeight != (one.Ascent+one.Descent)*2 {
+		t.Errorf("行距为 2 时行高应为 %d，实际 %d", (one.Ascent+one.Descent)*2, spaced.LineHeight)
+	}
+}
+
+func TestTextDrawer_DrawTextBox(t *testing.T) {
+	d := goDrawer(t)
+	defer d.Close()
+
+	img := image.NewRGBA(image.Rect(0, 0, 100, 60))
+	bg := color.RGBA{R: 255, A: 255}
+	m := d.MeasureText("Hello")
+
+	// 超出右下角的文本框移动到图片范围内
+	box := d.DrawTextBox(img, "Hello", 90, 50, color.White, bg, 4)
+	if box.Max.X != 100 || box.Max.Y != 60 {
+		t.Errorf("文本框应限制在图片范围内，实际 %v", box)
+	}
+	if box.Dx() != m.Width+8 || box.Dy() != m.Height+8 {
+		t.Errorf("文本框尺寸应为文本尺寸加上内边距，实际 %v", box)
+	}
+	if got := img.RGBAAt(box.Min.X+1, box.Min.Y+1); got != bg {
+		t.Errorf("背景颜色应为 %v，实际 %v", bg, got)
+	}
+
+	// 描边计入文本框
+	d.SetOutline(2, color.Black)
+	if outlined := d.DrawTextBox(img, "Hello", 0, 0, color.White, nil, 4); outlined.Dx() != m.Width+12 {
+		t.Errorf("描边宽度应计入文本框，实际 %v", outlined)
+	}
+}
+
+func TestTextDrawer_Outline(t *testing.T) {
+	d := goDrawer(t)
+	defer d.Close()
+
+	count := func(img *image.RGBA, c color.RGBA) int {
+		n := 0
+		for y := 0; y < img.Rect.Dy(); y++ {
+			for x := 0; x < img.Rect.Dx(); x++ {
+				if img.RGBAAt(x, y) == c {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	red := color.RGBA{R: 255, A: 255}
+
+	plain := image.NewRGBA(image.Rect(0, 0, 80, 40))
+	d.DrawText(plain, "Hi", 10, 25, color.White)
+	if count(plain, red) != 0 {
+		t.Fatal("未设置描边时不应绘制描边")
+	}
+
+	outlined := image.NewRGBA(image.Rect(0, 0, 80, 40))
+	d.SetOutline(2, red)
+	d.DrawText(outlined, "Hi", 10, 25, color.White)
+	if count(outlined, red) == 0 {
+		t.Error("描边颜色应出现在文字周围")
+	}
+}
+
+func TestNewTextDrawerFS(t *testing.T) {
+	fsys := fstest.MapFS{"fonts/go.ttf": {Data: goregular.TTF}}
+	d, err := NewTextDrawerFS(fsys, "fonts/go.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if m := d.MeasureText("Hello"); m.Width <= 0 {
+		t.Errorf("文本宽度应大于 0，实际 %+v", m)
+	}
+
+	if _, err := NewTextDrawerFS(fsys, "fonts/missing.ttf"); err == nil {
+		t.Error("字体不存在时应返回错误")
+	}
+	if _, err := NewTextDrawerFromBytes([]byte("not a font")); err == nil {
+		t.Error("无效的字体数据应返回错误")
+	}
+}
+
+func TestTextDrawer_AddFallback(t *testing.T) {
+	d := goDrawer(t)
+	defer d.Close()
+	if err := d.AddFallback(gomono.TTF); err != nil {
+		t.Fatal(err)
+	}
+	face, ok := d.Face().(*fallbackFace)
+	if !ok {
+		t.Fatalf("添加回退字体后应使用 fallbackFace，实际 %T", d.Face())
+	}
+	if d.fontSize != 20 || len(face.faces) != 2 {
+		t.Fatalf("添加回退字体后应保持字号并为每个字体创建 Face: %v, %d", d.fontSize, len(face.faces))
+	}
+	// 主字体包含的字形优先使用主字体，都不包含时也使用主字体
+	if face.face('A') != 0 || face.face('中') != 0 {
+		t.Error("主字体包含的字形应使用主字体")
+	}
+	if err := d.AddFallback([]byte("not a font")); err == nil {
+		t.Error("无效的字体数据应返回错误")
+	}
+	if len(d.fonts) != 2 || d.fontSize != 20 || d.Face() != face {
+		t.Error("添加失败时不应修改字体、字号和 Face")
+	}
+
+	unifont, err := os.ReadFile("./fonts/unifont-sc.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddFallback(unifont); err != nil {
+		t.Fatal(err)
+	}
+	face = d.Face().(*fallbackFace)
+	if face.face('A') != 0 || face.face('中') != 2 {
+		t.Errorf("中文应使用回退字体: A=%d 中=%d", face.face('A'), face.face('中'))
+	}
+	if m := d.MeasureText("中"); m.Width <= 0 {
+		t.Errorf("中文宽度应大于 0，实际 %+v", m)
+	}
+}
@@ -28,15 +28,14 @@ func TestSAM2Refactored(t *testing.T) {
 	}
 	defer imgCtx.Destroy()
 
-	points := []sam2.Point{
-		{X: 367, Y: 168, Label: sam2.LabelBoxTopLeft},  // 左上
-		{X: 441, Y: 349, Label: sam2.LabelBoxBotRight}, // 右下
-	}
-	imgResult, score, err := imgCtx.Decode(points)
+	// 框选提示，可以与前景/背景点组合，多个框在一次解码中各输出一个 Mask
+	results, err := imgCtx.DecodePrompt(sam2.Prompt{
+		Boxes: []sam2.Box{{X1: 367, Y1: 168, X2: 441, Y2: 349}},
+	})
 	if err != nil {
 		t.Fatalf("Mask Decode 失败: %v", err)
 	}
 
-	fmt.Printf("Mask generated, score: %.4f\n", score)
-	imageutil.Save("output_mask.png", imgResult, 100)
+	fmt.Printf("Mask generated, score: %.4f\n", results[0].Score)
+	imageutil.Save("output_mask.png", results[0].Image(), 100)
 }
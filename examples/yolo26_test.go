@@ -31,7 +31,7 @@ func TestYOLO26Det(t *testing.T) {
 	draw.Draw(targetImg, img.Bounds(), img, img.Bounds().Min, draw.Src)
 	fmt.Printf("检测到目标: %d 个\n", len(results))
 	for _, res := range results {
-		fmt.Printf("Class: %d, Score: %.2f, Box: %v\n", res.ClassID, res.Score, res.Box)
+		fmt.Printf("Class: %d (%s), Score: %.2f, Box: %v\n", res.ClassID, res.ClassName, res.Score, res.Box)
 		imageutil.DrawThickRectOutline(targetImg, res.Box, color.RGBA{R: 255, G: 0, B: 0, A: 255}, 3)
 	}
 	imageutil.Save("yolo26_det.jpg", targetImg, 50)
@@ -56,8 +56,8 @@ func TestYOLO26Seg(t *testing.T) {
 
 	fmt.Printf("检测到目标: %d 个\n", len(results))
 	for idx, res := range results {
-		fmt.Printf("Class: %d, Score: %.2f, Box: %v\n", res.ClassID, res.Score, res.Box)
-		imageutil.Save(fmt.Sprintf("yolo26_seg_mask_%d.png", idx), res.Mask, 100)
+		fmt.Printf("Class: %d (%s), Score: %.2f, Box: %v\n", res.ClassID, res.ClassName, res.Score, res.Box)
+		imageutil.Save(fmt.Sprintf("yolo26_seg_mask_%d.png", idx), res.FullMask(), 100)
 	}
 }
 
@@ -79,7 +79,7 @@ func TestYOLO26Cls(t *testing.T) {
 	}
 
 	for _, res := range results {
-		fmt.Printf("Class: %d, Score: %.5f\n", res.ClassID, res.Score)
+		fmt.Printf("Class: %d (%s), Score: %.5f\n", res.ClassID, res.ClassName, res.Score)
 	}
 }
 
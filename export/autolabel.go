@@ -0,0 +1,119 @@
+package export
+
+import (
+	"fmt"
+	"github.com/getcharzp/go-vision"
+	"github.com/up-zero/gotool/imageutil"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// annotator LabelMe 与 CVAT 共用的添加标注的方法
+type annotator interface {
+	AddDetections(results []vision.DetResult)
+	AddSegmentations(results []vision.SegResult, opts vision.ContourOptions)
+	AddPoses(results []vision.PoseResult, threshold float32)
+	AddOBBs(results []vision.OBBResult)
+}
+
+var (
+	_ annotator = (*LabelMe)(nil)
+	_ annotator = (*CVATImage)(nil)
+)
+
+// AutoLabelMe 逐张图片推理，在图片旁写入同名的 LabelMe JSON 作为预标注
+//
+// # Params:
+//
+//	engine: vision.Detector、vision.Segmenter、vision.PoseEstimator、vision.OBBDetector 之一，
+//	  例如 yolov11.SegEngine 或 sahi.Detector，类别名称来自引擎的类别名称表
+//	paths: 图片路径
+//	opts: 分割 Mask 转多边形的参数
+func AutoLabelMe(engine any, paths []string, opts vision.ContourOptions) error {
+	for _, path := range paths {
+		img, err := imageutil.Open(path)
+		if err != nil {
+			return fmt.Errorf("打开图片 %s 失败: %w", path, err)
+		}
+		l := NewLabelMe(filepath.Base(path), img.Bounds().Dx(), img.Bounds().Dy())
+		if err := predictInto(engine, img, l, opts); err != nil {
+			return fmt.Errorf("图片 %s 推理失败: %w", path, err)
+		}
+
+		f, err := os.Create(strings.TrimSuffix(path, filepath.Ext(path)) + ".json")
+		if err != nil {
+			return fmt.Errorf("创建 LabelMe 文件失败: %w", err)
+		}
+		err = l.Write(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("写入 LabelMe 文件失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// AutoLabelCVAT 逐张图片推理，生成 CVAT for images 1.1 格式的预标注
+//
+// 类别定义为引擎的类别名称表 (engine 实现 vision.Namer 时)，图片名称为文件名
+//
+// # Params:
+//
+//	engine: vision.Detector、vision.Segmenter、vision.PoseEstimator、vision.OBBDetector 之一
+//	paths: 图片路径
+//	opts: 分割 Mask 转多边形的参数
+func AutoLabelCVAT(engine any, paths []string, opts vision.ContourOptions) (*CVAT, error) {
+	var names []string
+	if n, ok := engine.(vision.Namer); ok {
+		names = n.Names()
+	}
+	c := NewCVAT(names)
+	for _, path := range paths {
+		img, err := imageutil.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("打开图片 %s 失败: %w", path, err)
+		}
+		ci := c.AddImage(filepath.Base(path), img.Bounds().Dx(), img.Bounds().Dy())
+		if err := predictInto(engine, img, ci, opts); err != nil {
+			return nil, fmt.Errorf("图片 %s 推理失败: %w", path, err)
+		}
+	}
+	return c, nil
+}
+
+// predictInto 根据引擎类型推理并添加标注
+func predictInto(engine any, img image.Image, a annotator, opts vision.ContourOptions) error {
+	switch e := engine.(type) {
+	case vision.Segmenter:
+		results, err := e.Predict(img)
+		if err != nil {
+			return err
+		}
+		a.AddSegmentations(results, opts)
+	case vision.PoseEstimator:
+		results, err := e.Predict(img)
+		if err != nil {
+			return err
+		}
+		a.AddPoses(results, DefaultKeyPointThreshold)
+	case vision.OBBDetector:
+		results, err := e.Predict(img)
+		if err != nil {
+			return err
+		}
+		a.AddOBBs(results)
+	case vision.Detector:
+		results, err := e.Predict(img)
+		if err != nil {
+			return err
+		}
+		a.AddDetections(results)
+	default:
+		return fmt.Errorf("不支持的引擎类型 %T", engine)
+	}
+	return nil
+}
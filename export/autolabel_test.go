@@ -0,0 +1,84 @@
+package export
+
+import (
+	"context"
+	"github.com/getcharzp/go-vision"
+	"github.com/up-zero/gotool/imageutil"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeDetector 对每张图片返回固定结果的检测引擎
+type fakeDetector struct{}
+
+func (fakeDetector) Predict(img image.Image) ([]vision.DetResult, error) {
+	return []vision.DetResult{{ClassID: 1, ClassName: "car", Score: 0.9, Box: image.Rect(1, 1, 5, 5)}}, nil
+}
+
+func (d fakeDetector) PredictContext(_ context.Context, img image.Image) ([]vision.DetResult, error) {
+	return d.Predict(img)
+}
+
+func (d fakeDetector) PredictBatch(imgs []image.Image) ([][]vision.DetResult, error) {
+	return nil, nil
+}
+
+func (d fakeDetector) PredictBatchContext(_ context.Context, imgs []image.Image) ([][]vision.DetResult, error) {
+	return nil, nil
+}
+
+func (fakeDetector) Destroy() {}
+
+func (fakeDetector) Names() []string { return []string{"person", "car"} }
+
+// writeImages 在临时目录中生成测试图片
+func writeImages(t *testing.T, n int) []string {
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < n; i++ {
+		path := filepath.Join(dir, string(rune('a'+i))+".png")
+		if err := imageutil.Save(path, image.NewRGBA(image.Rect(0, 0, 16, 8)), 100); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestAutoLabelMe(t *testing.T) {
+	paths := writeImages(t, 2)
+	if err := AutoLabelMe(fakeDetector{}, paths, vision.ContourOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(filepath.Dir(paths[0]), "a.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	l, err := ReadLabelMe(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.ImagePath != "a.png" || l.ImageWidth != 16 || l.ImageHeight != 8 || len(l.Shapes) != 1 || l.Shapes[0].Label != "car" {
+		t.Errorf("标注 %+v", l)
+	}
+}
+
+func TestAutoLabelCVAT(t *testing.T) {
+	c, err := AutoLabelCVAT(fakeDetector{}, writeImages(t, 2), vision.ContourOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Labels) != 2 || c.Labels[1].Name != "car" {
+		t.Errorf("类别定义应来自引擎的类别名称表，实际 %v", c.Labels)
+	}
+	if len(c.Images) != 2 || c.Images[1].Name != "b.png" || len(c.Images[1].Boxes) != 1 {
+		t.Errorf("图片 %+v", c.Images)
+	}
+
+	if _, err := AutoLabelCVAT(struct{}{}, writeImages(t, 1), vision.ContourOptions{}); err == nil {
+		t.Error("不支持的引擎类型应返回错误")
+	}
+}
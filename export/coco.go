@@ -0,0 +1,330 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"io"
+	"math"
+	"strconv"
+)
+
+// DefaultKeyPointThreshold 默认的关键点置信度阈值，置信度大于等于该值的关键点视为可见
+const DefaultKeyPointThreshold float32 = 0.5
+
+// COCO COCO 格式的数据集，对应 instances_*.json 与 person_keypoints_*.json
+//
+// 类别 ID 为 classID+1，图片与标注的 ID 从 1 开始，classID 为负数的结果不会被添加
+type COCO struct {
+	Images      []COCOImage      `json:"images"`
+	Annotations []COCOAnnotation `json:"annotations"`
+	Categories  []COCOCategory   `json:"categories"`
+}
+
+// COCOImage 图片信息
+type COCOImage struct {
+	ID       int    `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+// COCOCategory 类别信息，姿态数据集包含关键点名称和骨架
+type COCOCategory struct {
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`
+	Supercategory string   `json:"supercategory"`
+	Keypoints     []string `json:"keypoints,omitempty"`
+	Skeleton      [][2]int `json:"skeleton,omitempty"` // 关键点索引从 1 开始
+}
+
+// COCOAnnotation 单个目标的标注
+type COCOAnnotation struct {
+	ID           int               `json:"id"`
+	ImageID      int               `json:"image_id"`
+	CategoryID   int               `json:"category_id"`
+	BBox         [4]float64        `json:"bbox"` // x, y, w, h
+	Area         float64           `json:"area"`
+	IsCrowd      int               `json:"iscrowd"`
+	Segmentation *COCOSegmentation `json:"segmentation,omitempty"`
+	Keypoints    []float64         `json:"keypoints,omitempty"` // x1, y1, v1, x2, y2, v2, ...
+	NumKeypoints int               `json:"num_keypoints"`
+	Score        float32           `json:"score,omitempty"` // 预测结果的得分，人工标注为 0
+}
+
+// Box 标注的检测框
+func (a COCOAnnotation) Box() image.Rectangle {
+	x, y := int(math.Round(a.BBox[0])), int(math.Round(a.BBox[1]))
+	return image.Rect(x, y, x+int(math.Round(a.BBox[2])), y+int(math.Round(a.BBox[3])))
+}
+
+// ClassID 标注对应的类别 ID
+func (a COCOAnnotation) ClassID() int {
+	return a.CategoryID - 1
+}
+
+// KeyPoints 标注的关键点，可见 (v=2) 的关键点 Score 为 1，被遮挡 (v=1) 的为 0.5，未标注的为 0
+func (a COCOAnnotation) KeyPoints() []vision.KeyPoint {
+	kpts := make([]vision.KeyPoint, len(a.Keypoints)/3)
+	for i := range kpts {
+		kpts[i] = vision.KeyPoint{
+			X:     int(math.Round(a.Keypoints[i*3])),
+			Y:     int(math.Round(a.Keypoints[i*3+1])),
+			Score: float32(a.Keypoints[i*3+2]) / 2,
+		}
+	}
+	return kpts
+}
+
+// COCOSegmentation 分割标注，多边形 (不支持孔洞) 或 RLE 二选一
+type COCOSegmentation struct {
+	Polygons [][]float64 // 每个多边形为 x1, y1, x2, y2, ...
+	RLE      *vision.RLE
+}
+
+// MarshalJSON 多边形编码为 [[x1, y1, ...]]，RLE 编码为 {"size": [h, w], "counts": "..."}
+func (s COCOSegmentation) MarshalJSON() ([]byte, error) {
+	if s.RLE != nil {
+		return json.Marshal(s.RLE)
+	}
+	return json.Marshal(s.Polygons)
+}
+
+// UnmarshalJSON 解析多边形或 RLE 格式的分割标注
+func (s *COCOSegmentation) UnmarshalJSON(data []byte) error {
+	var polygons [][]float64
+	if err := json.Unmarshal(data, &polygons); err == nil {
+		*s = COCOSegmentation{Polygons: polygons}
+		return nil
+	}
+	rle := new(vision.RLE)
+	if err := json.Unmarshal(data, rle); err != nil {
+		return fmt.Errorf("分割标注格式错误: %w", err)
+	}
+	*s = COCOSegmentation{RLE: rle}
+	return nil
+}
+
+// NewCOCO 创建 COCO 数据集
+//
+// # Params:
+//
+//	names: 类别名称表，例如引擎配置中的 Names
+func NewCOCO(names []string) *COCO {
+	c := &COCO{Images: []COCOImage{}, Annotations: []COCOAnnotation{}, Categories: []COCOCategory{}}
+	for i, name := range names {
+		c.Categories = append(c.Categories, COCOCategory{ID: i + 1, Name: name})
+	}
+	return c
+}
+
+// ReadCOCO 读取 COCO 格式的 JSON
+func ReadCOCO(r io.Reader) (*COCO, error) {
+	c := new(COCO)
+	if err := json.NewDecoder(r).Decode(c); err != nil {
+		return nil, fmt.Errorf("解析 COCO JSON 失败: %w", err)
+	}
+	return c, nil
+}
+
+// Write 写入 COCO 格式的 JSON
+func (c *COCO) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(c)
+}
+
+// AddImage 添加图片，返回图片 ID
+func (c *COCO) AddImage(fileName string, width, height int) int {
+	id := len(c.Images) + 1
+	c.Images = append(c.Images, COCOImage{ID: id, FileName: fileName, Width: width, Height: height})
+	return id
+}
+
+// ImageAnnotations 获取图片的所有标注
+func (c *COCO) ImageAnnotations(imageID int) []COCOAnnotation {
+	var anns []COCOAnnotation
+	for _, a := range c.Annotations {
+		if a.ImageID == imageID {
+			anns = append(anns, a)
+		}
+	}
+	return anns
+}
+
+// AddDetections 添加目标检测结果
+func (c *COCO) AddDetections(imageID int, results []vision.DetResult) {
+	for _, res := range results {
+		c.add(res.ClassID, res.ClassName, COCOAnnotation{
+			ImageID: imageID,
+			BBox:    bbox(res.Box),
+			Area:    float64(res.Box.Dx() * res.Box.Dy()),
+			Score:   res.Score,
+		})
+	}
+}
+
+// AddSegmentations 添加分割结果，Mask 编码为 RLE (保留孔洞)
+//
+// Mask 的尺寸为 MaskSize，与图片尺寸一致时 (MaskResolution 为 MaskOriginal) 才能被评估工具正确读取
+func (c *COCO) AddSegmentations(imageID int, results []vision.SegResult) {
+	for _, res := range results {
+		rle := res.RLE()
+		c.add(res.ClassID, res.ClassName, COCOAnnotation{
+			ImageID:      imageID,
+			BBox:         bbox(rle.BBox()),
+			Area:         float64(rle.Area()),
+			Segmentation: &COCOSegmentation{RLE: &rle},
+			Score:        res.Score,
+		})
+	}
+}
+
+// AddSegmentationPolygons 添加分割结果，Mask 转为多边形，孔洞被忽略
+//
+// # Params:
+//
+//	imageID: 图片 ID
+//	results: 分割结果
+//	opts: 轮廓提取参数，例如 Tolerance 为 1 时可以大幅减少顶点数量
+func (c *COCO) AddSegmentationPolygons(imageID int, results []vision.SegResult, opts vision.ContourOptions) {
+	for _, res := range results {
+		var (
+			polygons [][]float64
+			area     float64
+			bounds   image.Rectangle
+		)
+		for _, p := range res.Polygons(opts) {
+			polygons = append(polygons, flatten(p.Outer))
+			// 只写入外轮廓，面积与写入的多边形保持一致，不扣除孔洞
+			area += vision.Polygon{Outer: p.Outer}.Area()
+			bounds = bounds.Union(pointsBounds(p.Outer))
+		}
+		if len(polygons) == 0 {
+			continue
+		}
+		c.add(res.ClassID, res.ClassName, COCOAnnotation{
+			ImageID:      imageID,
+			BBox:         bbox(bounds),
+			Area:         area,
+			Segmentation: &COCOSegmentation{Polygons: polygons},
+			Score:        res.Score,
+		})
+	}
+}
+
+// AddPoses 添加姿态估计结果
+//
+// 类别未设置关键点名称时自动补全，17 个关键点时使用 COCO 的关键点名称和骨架
+//
+// # Params:
+//
+//	imageID: 图片 ID
+//	results: 姿态估计结果
+//	threshold: 关键点置信度大于等于该值时标记为可见，否则标记为未标注，例如 DefaultKeyPointThreshold
+func (c *COCO) AddPoses(imageID int, results []vision.PoseResult, threshold float32) {
+	for _, res := range results {
+		kpts := make([]float64, 0, len(res.KeyPoints)*3)
+		numVisible := 0
+		for _, kp := range res.KeyPoints {
+			if kp.Score >= threshold {
+				kpts = append(kpts, float64(kp.X), float64(kp.Y), 2)
+				numVisible++
+			} else {
+				kpts = append(kpts, 0, 0, 0)
+			}
+		}
+		if !c.add(res.ClassID, res.ClassName, COCOAnnotation{
+			ImageID:      imageID,
+			BBox:         bbox(res.Box),
+			Area:         float64(res.Box.Dx() * res.Box.Dy()),
+			Keypoints:    kpts,
+			NumKeypoints: numVisible,
+			Score:        res.Score,
+		}) {
+			continue
+		}
+		if cat := &c.Categories[res.ClassID]; len(cat.Keypoints) == 0 {
+			cat.Keypoints, cat.Skeleton = keyPointNames(len(res.KeyPoints))
+		}
+	}
+}
+
+// AddOBBs 添加旋转目标检测结果，旋转框的 4 个顶点作为多边形分割标注，bbox 为其外接矩形
+func (c *COCO) AddOBBs(imageID int, results []vision.OBBResult) {
+	for _, res := range results {
+		corners := res.Corners[:]
+		c.add(res.ClassID, res.ClassName, COCOAnnotation{
+			ImageID:      imageID,
+			BBox:         bbox(pointsBounds(corners)),
+			Area:         vision.Polygon{Outer: corners}.Area(),
+			Segmentation: &COCOSegmentation{Polygons: [][]float64{flatten(corners)}},
+			Score:        res.Score,
+		})
+	}
+}
+
+// add 添加标注并分配标注 ID 和类别 ID，classID 为负数时忽略该标注并返回 false
+func (c *COCO) add(classID int, name string, a COCOAnnotation) bool {
+	if classID < 0 {
+		return false
+	}
+	a.ID = len(c.Annotations) + 1
+	a.CategoryID = c.category(classID, name)
+	c.Annotations = append(c.Annotations, a)
+	return true
+}
+
+// category 获取类别 ID，类别表中不存在时自动补全
+func (c *COCO) category(classID int, name string) int {
+	for len(c.Categories) <= classID {
+		id := len(c.Categories)
+		c.Categories = append(c.Categories, COCOCategory{ID: id + 1, Name: strconv.Itoa(id)})
+	}
+	if cat := &c.Categories[classID]; name != "" && cat.Name == strconv.Itoa(classID) {
+		cat.Name = name
+	}
+	return classID + 1
+}
+
+// keyPointNames 关键点名称和骨架 (索引从 1 开始)
+func keyPointNames(n int) ([]string, [][2]int) {
+	if n == len(vision.COCOKeyPoints) {
+		skeleton := make([][2]int, len(vision.COCOSkeleton))
+		for i, pair := range vision.COCOSkeleton {
+			skeleton[i] = [2]int{pair[0] + 1, pair[1] + 1}
+		}
+		return vision.COCOKeyPoints, skeleton
+	}
+	names := make([]string, n)
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+	return names, nil
+}
+
+// bbox 矩形转为 COCO 的 [x, y, w, h]
+func bbox(r image.Rectangle) [4]float64 {
+	return [4]float64{float64(r.Min.X), float64(r.Min.Y), float64(r.Dx()), float64(r.Dy())}
+}
+
+// flatten 顶点展开为 x1, y1, x2, y2, ...
+func flatten(pts []image.Point) []float64 {
+	values := make([]float64, 0, len(pts)*2)
+	for _, p := range pts {
+		values = append(values, float64(p.X), float64(p.Y))
+	}
+	return values
+}
+
+// pointsBounds 顶点的外接矩形
+func pointsBounds(pts []image.Point) image.Rectangle {
+	if len(pts) == 0 {
+		return image.Rectangle{}
+	}
+	r := image.Rectangle{Min: pts[0], Max: pts[0]}
+	for _, p := range pts[1:] {
+		r.Min.X, r.Min.Y = min(r.Min.X, p.X), min(r.Min.Y, p.Y)
+		r.Max.X, r.Max.Y = max(r.Max.X, p.X), max(r.Max.Y, p.Y)
+	}
+	return r
+}
@@ -0,0 +1,118 @@
+package export
+
+import (
+	"bytes"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestCOCO_RoundTrip(t *testing.T) {
+	c := NewCOCO([]string{"person", "car"})
+	imageID := c.AddImage("test.png", 20, 10)
+
+	c.AddDetections(imageID, []vision.DetResult{
+		{ClassID: 1, ClassName: "car", Score: 0.9, Box: image.Rect(2, 3, 8, 9)},
+	})
+
+	mask := image.NewGray(image.Rect(10, 0, 15, 5))
+	for y := 1; y < 4; y++ {
+		for x := 11; x < 14; x++ {
+			mask.Pix[mask.PixOffset(x, y)] = 255
+		}
+	}
+	seg := vision.SegResult{ClassID: 0, Score: 0.8, Box: mask.Rect, Mask: mask, MaskSize: image.Pt(20, 10)}
+	c.AddSegmentations(imageID, []vision.SegResult{seg})
+	c.AddSegmentationPolygons(imageID, []vision.SegResult{seg}, vision.ContourOptions{})
+
+	kpts := make([]vision.KeyPoint, 17)
+	kpts[0] = vision.KeyPoint{X: 5, Y: 4, Score: 0.9}
+	c.AddPoses(imageID, []vision.PoseResult{{ClassID: 0, Score: 0.7, Box: image.Rect(1, 1, 9, 9), KeyPoints: kpts}}, DefaultKeyPointThreshold)
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadCOCO(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Fatalf("读回的数据集与写入的不一致:\n%+v\n%+v", got, c)
+	}
+
+	anns := got.ImageAnnotations(imageID)
+	if len(anns) != 4 {
+		t.Fatalf("应有 4 个标注，实际 %d", len(anns))
+	}
+	if det := anns[0]; det.ClassID() != 1 || det.Box() != image.Rect(2, 3, 8, 9) {
+		t.Errorf("检测标注 %+v", det)
+	}
+
+	// RLE 与多边形的面积、外接矩形一致
+	rle, poly := anns[1], anns[2]
+	if rle.Segmentation.RLE == nil || rle.Area != 9 || rle.Box() != image.Rect(11, 1, 14, 4) {
+		t.Errorf("RLE 标注 %+v", rle)
+	}
+	if !reflect.DeepEqual(rle.Segmentation.RLE.Decode().Pix, seg.FullMask().Pix) {
+		t.Error("RLE 解码结果与 Mask 不一致")
+	}
+	if len(poly.Segmentation.Polygons) != 1 || poly.Area != 9 || poly.Box() != rle.Box() {
+		t.Errorf("多边形标注 %+v", poly)
+	}
+
+	// 姿态类别补全了 COCO 的关键点名称和骨架
+	pose := anns[3]
+	if pose.NumKeypoints != 1 || pose.KeyPoints()[0] != (vision.KeyPoint{X: 5, Y: 4, Score: 1}) {
+		t.Errorf("姿态标注 %+v", pose)
+	}
+	if cat := got.Categories[0]; len(cat.Keypoints) != 17 || cat.Skeleton[0] != [2]int{16, 14} {
+		t.Errorf("姿态类别 %+v", cat)
+	}
+}
+
+func TestCOCO_Category(t *testing.T) {
+	// 类别表中不存在的类别自动补全
+	c := NewCOCO(nil)
+	c.AddDetections(c.AddImage("a.png", 10, 10), []vision.DetResult{{ClassID: 2, ClassName: "car"}})
+	if len(c.Categories) != 3 || c.Categories[2].Name != "car" || c.Categories[0].Name != "0" {
+		t.Errorf("类别表 %+v", c.Categories)
+	}
+	if c.Annotations[0].CategoryID != 3 {
+		t.Errorf("类别 ID 应为 3，实际 %d", c.Annotations[0].CategoryID)
+	}
+}
+
+func TestCOCO_NegativeClassID(t *testing.T) {
+	c := NewCOCO([]string{"person"})
+	imageID := c.AddImage("a.png", 10, 10)
+	c.AddDetections(imageID, []vision.DetResult{{ClassID: -1}, {ClassID: 0}})
+	c.AddPoses(imageID, []vision.PoseResult{{ClassID: -1, KeyPoints: make([]vision.KeyPoint, 17)}}, DefaultKeyPointThreshold)
+	if len(c.Annotations) != 1 || c.Annotations[0].CategoryID != 1 {
+		t.Errorf("classID 为负数的结果应被忽略，实际 %+v", c.Annotations)
+	}
+	if len(c.Categories) != 1 || len(c.Categories[0].Keypoints) != 0 {
+		t.Errorf("类别表不应被修改，实际 %+v", c.Categories)
+	}
+}
+
+func TestCOCO_SegmentationPolygonsHole(t *testing.T) {
+	// 5x5 的方块，中心 1 个像素为孔洞
+	mask := image.NewGray(image.Rect(0, 0, 7, 7))
+	for y := 1; y < 6; y++ {
+		for x := 1; x < 6; x++ {
+			if x != 3 || y != 3 {
+				mask.Pix[mask.PixOffset(x, y)] = 255
+			}
+		}
+	}
+	c := NewCOCO([]string{"person"})
+	c.AddSegmentationPolygons(c.AddImage("a.png", 7, 7), []vision.SegResult{{Box: mask.Rect, Mask: mask, MaskSize: image.Pt(7, 7)}}, vision.ContourOptions{})
+
+	// 孔洞不写入多边形，面积也不扣除孔洞
+	ann := c.Annotations[0]
+	if len(ann.Segmentation.Polygons) != 1 || ann.Area != 25 {
+		t.Errorf("多边形标注 %+v", ann)
+	}
+}
@@ -0,0 +1,298 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CVAT CVAT for images 1.1 格式的标注，整个数据集一个 XML 文件
+type CVAT struct {
+	XMLName xml.Name     `xml:"annotations"`
+	Version string       `xml:"version"`
+	Labels  []CVATLabel  `xml:"meta>task>labels>label"`
+	Images  []*CVATImage `xml:"image"`
+}
+
+// CVATLabel 类别定义，Type 为空时 CVAT 视为 any
+type CVATLabel struct {
+	Name string `xml:"name"`
+	Type string `xml:"type,omitempty"`
+	// Sublabels 骨架类别的关键点，每个关键点为一个 points 类型的子类别
+	Sublabels []CVATLabel `xml:"sublabels>label,omitempty"`
+}
+
+// CVAT 类别类型
+const (
+	CVATTypeAny       = "any"       // 任意形状，同一类别用于多种形状时使用
+	CVATTypeRectangle = "rectangle" // 检测框 (包括旋转框)
+	CVATTypePolygon   = "polygon"   // 多边形
+	CVATTypePoints    = "points"    // 关键点，骨架的子类别
+	CVATTypeSkeleton  = "skeleton"  // 骨架
+)
+
+// CVATImage 单张图片的标注
+type CVATImage struct {
+	ID        int            `xml:"id,attr"`
+	Name      string         `xml:"name,attr"`
+	Width     int            `xml:"width,attr"`
+	Height    int            `xml:"height,attr"`
+	Boxes     []CVATBox      `xml:"box"`
+	Polygons  []CVATPolygon  `xml:"polygon"`
+	Skeletons []CVATSkeleton `xml:"skeleton"`
+
+	cvat *CVAT // 所属的数据集，用于补全类别定义
+}
+
+// CVATBox 检测框，Rotation 为绕中心顺时针旋转的角度
+type CVATBox struct {
+	Label    string  `xml:"label,attr"`
+	Occluded int     `xml:"occluded,attr"`
+	XTL      float64 `xml:"xtl,attr"`
+	YTL      float64 `xml:"ytl,attr"`
+	XBR      float64 `xml:"xbr,attr"`
+	YBR      float64 `xml:"ybr,attr"`
+	Rotation float64 `xml:"rotation,attr,omitempty"`
+	ZOrder   int     `xml:"z_order,attr"`
+}
+
+// CVATPolygon 多边形，Points 格式为 x1,y1;x2,y2;...
+type CVATPolygon struct {
+	Label    string `xml:"label,attr"`
+	Occluded int    `xml:"occluded,attr"`
+	Points   string `xml:"points,attr"`
+	ZOrder   int    `xml:"z_order,attr"`
+}
+
+// CVATSkeleton 骨架 (姿态)，每个关键点为一个 points 元素
+type CVATSkeleton struct {
+	Label    string       `xml:"label,attr"`
+	Occluded int          `xml:"occluded,attr"`
+	ZOrder   int          `xml:"z_order,attr"`
+	Points   []CVATPoints `xml:"points"`
+}
+
+// CVATPoints 骨架中的单个关键点，Outside 为 1 表示未标注
+type CVATPoints struct {
+	Label    string `xml:"label,attr"`
+	Outside  int    `xml:"outside,attr"`
+	Occluded int    `xml:"occluded,attr"`
+	Points   string `xml:"points,attr"`
+}
+
+// NewCVAT 创建 CVAT 标注
+//
+// # Params:
+//
+//	names: 类别名称表，例如引擎的 Names，结果中出现的其他类别在添加时补全
+func NewCVAT(names []string) *CVAT {
+	c := &CVAT{Version: "1.1"}
+	for _, name := range names {
+		c.Labels = append(c.Labels, CVATLabel{Name: name})
+	}
+	return c
+}
+
+// ReadCVAT 读取 CVAT for images 1.1 格式的 XML
+func ReadCVAT(r io.Reader) (*CVAT, error) {
+	c := new(CVAT)
+	if err := xml.NewDecoder(r).Decode(c); err != nil {
+		return nil, fmt.Errorf("解析 CVAT XML 失败: %w", err)
+	}
+	for _, img := range c.Images {
+		img.cvat = c
+	}
+	return c, nil
+}
+
+// Write 写入 CVAT for images 1.1 格式的 XML
+func (c *CVAT) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// AddImage 添加图片，返回用于添加标注的 *CVATImage
+func (c *CVAT) AddImage(name string, width, height int) *CVATImage {
+	img := &CVATImage{ID: len(c.Images), Name: name, Width: width, Height: height, cvat: c}
+	c.Images = append(c.Images, img)
+	return img
+}
+
+// label 返回类别名称，类别定义中不存在时补全，并记录类别的类型
+//
+// 同一类别用于多种形状时类型为 any
+func (c *CVAT) label(name, typ string) *CVATLabel {
+	for i := range c.Labels {
+		l := &c.Labels[i]
+		if l.Name != name {
+			continue
+		}
+		if l.Type == "" {
+			l.Type = typ
+		} else if l.Type != typ {
+			l.Type = CVATTypeAny
+		}
+		return l
+	}
+	c.Labels = append(c.Labels, CVATLabel{Name: name, Type: typ})
+	return &c.Labels[len(c.Labels)-1]
+}
+
+// AddDetections 添加目标检测结果
+func (img *CVATImage) AddDetections(results []vision.DetResult) {
+	for _, res := range results {
+		img.Boxes = append(img.Boxes, CVATBox{
+			Label: img.label(res.ClassID, res.ClassName, CVATTypeRectangle).Name,
+			XTL:   float64(res.Box.Min.X),
+			YTL:   float64(res.Box.Min.Y),
+			XBR:   float64(res.Box.Max.X),
+			YBR:   float64(res.Box.Max.Y),
+		})
+	}
+}
+
+// AddSegmentations 添加分割结果，Mask 的每个连通域为一个 polygon，孔洞被忽略
+func (img *CVATImage) AddSegmentations(results []vision.SegResult, opts vision.ContourOptions) {
+	for _, res := range results {
+		img.AddPolygons(labelName(res.ClassID, res.ClassName), res.Polygons(opts))
+	}
+}
+
+// AddPolygons 添加多边形，例如 sam2.Result 的 Polygons，孔洞被忽略
+//
+// # Params:
+//
+//	label: 类别名称
+//	polys: 多边形
+func (img *CVATImage) AddPolygons(label string, polys []vision.Polygon) {
+	label = img.cvat.label(label, CVATTypePolygon).Name
+	for _, p := range polys {
+		img.Polygons = append(img.Polygons, CVATPolygon{Label: label, Points: cvatPoints(p.Outer...)})
+	}
+}
+
+// AddPoses 添加姿态估计结果，置信度低于 threshold 的关键点标记为 outside
+//
+// 类别定义为 skeleton 类型，关键点名称作为 points 类型的子类别
+//
+// # Params:
+//
+//	results: 姿态估计结果
+//	threshold: 关键点置信度阈值，例如 DefaultKeyPointThreshold
+func (img *CVATImage) AddPoses(results []vision.PoseResult, threshold float32) {
+	for _, res := range results {
+		names, _ := keyPointNames(len(res.KeyPoints))
+		label := img.label(res.ClassID, res.ClassName, CVATTypeSkeleton)
+		if len(label.Sublabels) == 0 {
+			for _, name := range names {
+				label.Sublabels = append(label.Sublabels, CVATLabel{Name: name, Type: CVATTypePoints})
+			}
+		}
+
+		skeleton := CVATSkeleton{Label: label.Name}
+		for i, kp := range res.KeyPoints {
+			outside := 0
+			if kp.Score < threshold {
+				outside = 1
+			}
+			skeleton.Points = append(skeleton.Points, CVATPoints{
+				Label:   names[i],
+				Outside: outside,
+				Points:  cvatPoints(image.Pt(kp.X, kp.Y)),
+			})
+		}
+		img.Skeletons = append(img.Skeletons, skeleton)
+	}
+}
+
+// AddOBBs 添加旋转目标检测结果，转为带 rotation 的检测框
+func (img *CVATImage) AddOBBs(results []vision.OBBResult) {
+	for _, res := range results {
+		c := res.Corners
+		w := math.Hypot(float64(c[1].X-c[0].X), float64(c[1].Y-c[0].Y))
+		h := math.Hypot(float64(c[2].X-c[1].X), float64(c[2].Y-c[1].Y))
+		cx, cy := float64(res.Center.X), float64(res.Center.Y)
+
+		// 图片坐标系 (y 轴向下) 中正角度为顺时针
+		rotation := math.Mod(float64(res.Angle)*180/math.Pi, 360)
+		if rotation < 0 {
+			rotation += 360
+		}
+		img.Boxes = append(img.Boxes, CVATBox{
+			Label:    img.label(res.ClassID, res.ClassName, CVATTypeRectangle).Name,
+			XTL:      roundTo(cx-w/2, 2),
+			YTL:      roundTo(cy-h/2, 2),
+			XBR:      roundTo(cx+w/2, 2),
+			YBR:      roundTo(cy+h/2, 2),
+			Rotation: roundTo(rotation, 2),
+		})
+	}
+}
+
+// label 返回类别定义，不存在时补全到数据集中
+func (img *CVATImage) label(classID int, className, typ string) *CVATLabel {
+	return img.cvat.label(labelName(classID, className), typ)
+}
+
+// Polygon 解析多边形的顶点
+func (p CVATPolygon) Polygon() ([]image.Point, error) {
+	return parseCVATPoints(p.Points)
+}
+
+// Point 解析关键点坐标
+func (p CVATPoints) Point() (image.Point, error) {
+	pts, err := parseCVATPoints(p.Points)
+	if err != nil {
+		return image.Point{}, err
+	}
+	return pts[0], nil
+}
+
+// cvatPoints 顶点转为 x1,y1;x2,y2;... 格式
+func cvatPoints(pts ...image.Point) string {
+	parts := make([]string, len(pts))
+	for i, p := range pts {
+		parts[i] = strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y)
+	}
+	return strings.Join(parts, ";")
+}
+
+// parseCVATPoints 解析 x1,y1;x2,y2;... 格式的顶点，坐标四舍五入为整数
+func parseCVATPoints(s string) ([]image.Point, error) {
+	var pts []image.Point
+	for _, part := range strings.Split(s, ";") {
+		xy := strings.Split(part, ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("顶点格式错误: %q", part)
+		}
+		x, err := strconv.ParseFloat(xy[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("顶点格式错误: %w", err)
+		}
+		y, err := strconv.ParseFloat(xy[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("顶点格式错误: %w", err)
+		}
+		pts = append(pts, image.Pt(round(x), round(y)))
+	}
+	return pts, nil
+}
+
+// roundTo 保留 n 位小数
+func roundTo(v float64, n int) float64 {
+	p := math.Pow10(n)
+	return math.Round(v*p) / p
+}
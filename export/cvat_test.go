@@ -0,0 +1,100 @@
+package export
+
+import (
+	"bytes"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCVAT_RoundTrip(t *testing.T) {
+	c := NewCVAT([]string{"person", "car"})
+	img := c.AddImage("a.jpg", 100, 50)
+	img.AddDetections([]vision.DetResult{{ClassID: 1, ClassName: "car", Box: image.Rect(1, 2, 30, 40)}})
+	img.AddPolygons("dog", []vision.Polygon{{Outer: []image.Point{{0, 0}, {5, 0}, {5, 5}}}})
+
+	kpts := []vision.KeyPoint{{X: 3, Y: 4, Score: 0.9}, {X: 0, Y: 0, Score: 0.1}}
+	img.AddPoses([]vision.PoseResult{{ClassName: "person", KeyPoints: kpts}}, DefaultKeyPointThreshold)
+
+	// 旋转 90 度的 20x10 旋转框
+	img.AddOBBs([]vision.OBBResult{{
+		ClassName: "ship",
+		Corners:   [4]image.Point{{55, 10}, {55, 30}, {45, 30}, {45, 10}},
+		Center:    image.Pt(50, 20),
+		Angle:     math.Pi / 2,
+	}})
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadCVAT(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.XMLName = c.XMLName
+	if !reflect.DeepEqual(got, c) {
+		t.Fatalf("读回的标注与写入的不一致:\n%+v\n%+v", got, c)
+	}
+
+	// 结果中出现的其他类别被补全到类别定义中
+	wantLabels := []CVATLabel{
+		{Name: "person", Type: CVATTypeSkeleton, Sublabels: []CVATLabel{
+			{Name: "0", Type: CVATTypePoints}, {Name: "1", Type: CVATTypePoints},
+		}},
+		{Name: "car", Type: CVATTypeRectangle},
+		{Name: "dog", Type: CVATTypePolygon},
+		{Name: "ship", Type: CVATTypeRectangle},
+	}
+	if !reflect.DeepEqual(got.Labels, wantLabels) {
+		t.Errorf("类别定义 %v，预期 %v", got.Labels, wantLabels)
+	}
+
+	gi := got.Images[0]
+	if pts, err := gi.Polygons[0].Polygon(); err != nil || !reflect.DeepEqual(pts, []image.Point{{0, 0}, {5, 0}, {5, 5}}) {
+		t.Errorf("多边形 %v, %v", pts, err)
+	}
+	sk := gi.Skeletons[0]
+	if p, _ := sk.Points[0].Point(); sk.Points[0].Label != "0" || p != image.Pt(3, 4) || sk.Points[1].Outside != 1 {
+		t.Errorf("骨架 %+v", sk)
+	}
+	want := CVATBox{Label: "ship", XTL: 40, YTL: 15, XBR: 60, YBR: 25, Rotation: 90}
+	if box := gi.Boxes[1]; box != want {
+		t.Errorf("旋转框 %+v，预期 %+v", box, want)
+	}
+}
+
+func TestCVAT_LabelTypes(t *testing.T) {
+	c := NewCVAT([]string{"person", "car", "cat"})
+	img := c.AddImage("a.jpg", 100, 50)
+	img.AddDetections([]vision.DetResult{{ClassName: "car", Box: image.Rect(1, 2, 30, 40)}})
+	img.AddPolygons("car", []vision.Polygon{{Outer: []image.Point{{0, 0}, {5, 0}, {5, 5}}}})
+
+	kpts := make([]vision.KeyPoint, len(vision.COCOKeyPoints))
+	img.AddPoses([]vision.PoseResult{{ClassName: "person", KeyPoints: kpts}, {ClassName: "person", KeyPoints: kpts}}, DefaultKeyPointThreshold)
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := buf.String()
+	for _, s := range []string{"<type>skeleton</type>", "<sublabels>", "<name>left_eye</name>", "<type>points</type>", "<type>any</type>"} {
+		if !strings.Contains(xml, s) {
+			t.Errorf("XML 中应包含 %s", s)
+		}
+	}
+
+	person, car, cat := c.Labels[0], c.Labels[1], c.Labels[2]
+	if person.Type != CVATTypeSkeleton || len(person.Sublabels) != len(vision.COCOKeyPoints) || person.Sublabels[1].Name != vision.COCOKeyPoints[1] {
+		t.Errorf("骨架类别应包含关键点子类别: %+v", person)
+	}
+	if car.Type != CVATTypeAny {
+		t.Errorf("同时用于检测框和多边形的类别应为 any，实际 %q", car.Type)
+	}
+	if cat.Type != "" || strings.Count(xml, "<type>") != 2+len(vision.COCOKeyPoints) {
+		t.Errorf("未使用的类别不应写入类型: %+v", cat)
+	}
+}
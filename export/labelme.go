@@ -0,0 +1,152 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"io"
+	"strconv"
+)
+
+// LabelMe LabelMe 格式的标注，每张图片一个 JSON 文件
+type LabelMe struct {
+	Version     string          `json:"version"`
+	Flags       map[string]bool `json:"flags"`
+	Shapes      []LabelMeShape  `json:"shapes"`
+	ImagePath   string          `json:"imagePath"` // 相对于 JSON 文件的图片路径
+	ImageData   *string         `json:"imageData"` // 内嵌的图片数据，预标注时为 null
+	ImageHeight int             `json:"imageHeight"`
+	ImageWidth  int             `json:"imageWidth"`
+}
+
+// LabelMeShape 单个形状
+type LabelMeShape struct {
+	Label       string          `json:"label"`
+	Points      [][2]float64    `json:"points"`
+	GroupID     *int            `json:"group_id"` // 同一个实例的形状 (例如检测框与关键点) 的分组
+	Description string          `json:"description"`
+	ShapeType   string          `json:"shape_type"` // rectangle、polygon、point
+	Flags       map[string]bool `json:"flags"`
+}
+
+// NewLabelMe 创建 LabelMe 标注
+//
+// # Params:
+//
+//	imagePath: 相对于 JSON 文件的图片路径，通常为图片文件名
+//	width, height: 图片尺寸
+func NewLabelMe(imagePath string, width, height int) *LabelMe {
+	return &LabelMe{
+		Version:     "5.5.0",
+		Flags:       map[string]bool{},
+		Shapes:      []LabelMeShape{},
+		ImagePath:   imagePath,
+		ImageHeight: height,
+		ImageWidth:  width,
+	}
+}
+
+// ReadLabelMe 读取 LabelMe 格式的 JSON
+func ReadLabelMe(r io.Reader) (*LabelMe, error) {
+	l := new(LabelMe)
+	if err := json.NewDecoder(r).Decode(l); err != nil {
+		return nil, fmt.Errorf("解析 LabelMe JSON 失败: %w", err)
+	}
+	return l, nil
+}
+
+// Write 写入 LabelMe 格式的 JSON
+func (l *LabelMe) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+// AddDetections 添加目标检测结果，每个检测框为一个 rectangle
+func (l *LabelMe) AddDetections(results []vision.DetResult) {
+	for _, res := range results {
+		l.addShape(labelName(res.ClassID, res.ClassName), "rectangle", nil, res.Box.Min, res.Box.Max)
+	}
+}
+
+// AddSegmentations 添加分割结果，Mask 的每个连通域为一个 polygon，孔洞被忽略
+func (l *LabelMe) AddSegmentations(results []vision.SegResult, opts vision.ContourOptions) {
+	for _, res := range results {
+		l.AddPolygons(labelName(res.ClassID, res.ClassName), res.Polygons(opts))
+	}
+}
+
+// AddPolygons 添加多边形，例如 sam2.Result 的 Polygons，孔洞被忽略
+//
+// # Params:
+//
+//	label: 类别名称
+//	polys: 多边形
+func (l *LabelMe) AddPolygons(label string, polys []vision.Polygon) {
+	for _, p := range polys {
+		l.addShape(label, "polygon", nil, p.Outer...)
+	}
+}
+
+// AddPoses 添加姿态估计结果，检测框为 rectangle，置信度不低于 threshold 的关键点为 point，
+// 同一个实例的形状使用相同的 group_id
+//
+// # Params:
+//
+//	results: 姿态估计结果
+//	threshold: 关键点置信度阈值，例如 DefaultKeyPointThreshold
+func (l *LabelMe) AddPoses(results []vision.PoseResult, threshold float32) {
+	for _, res := range results {
+		group := l.nextGroup()
+		l.addShape(labelName(res.ClassID, res.ClassName), "rectangle", &group, res.Box.Min, res.Box.Max)
+
+		names, _ := keyPointNames(len(res.KeyPoints))
+		for i, kp := range res.KeyPoints {
+			if kp.Score >= threshold {
+				l.addShape(names[i], "point", &group, image.Pt(kp.X, kp.Y))
+			}
+		}
+	}
+}
+
+// AddOBBs 添加旋转目标检测结果，旋转框的 4 个顶点为一个 polygon
+func (l *LabelMe) AddOBBs(results []vision.OBBResult) {
+	for _, res := range results {
+		l.addShape(labelName(res.ClassID, res.ClassName), "polygon", nil, res.Corners[:]...)
+	}
+}
+
+// addShape 添加形状
+func (l *LabelMe) addShape(label, shapeType string, group *int, pts ...image.Point) {
+	shape := LabelMeShape{
+		Label:     label,
+		Points:    make([][2]float64, len(pts)),
+		GroupID:   group,
+		ShapeType: shapeType,
+		Flags:     map[string]bool{},
+	}
+	for i, p := range pts {
+		shape.Points[i] = [2]float64{float64(p.X), float64(p.Y)}
+	}
+	l.Shapes = append(l.Shapes, shape)
+}
+
+// nextGroup 下一个未使用的 group_id
+func (l *LabelMe) nextGroup() int {
+	group := 0
+	for _, s := range l.Shapes {
+		if s.GroupID != nil && *s.GroupID >= group {
+			group = *s.GroupID + 1
+		}
+	}
+	return group
+}
+
+// labelName 标注使用的类别名称，名称为空时使用类别 ID
+func labelName(classID int, className string) string {
+	if className != "" {
+		return className
+	}
+	return strconv.Itoa(classID)
+}
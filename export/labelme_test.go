@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bytes"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestLabelMe_RoundTrip(t *testing.T) {
+	l := NewLabelMe("test.png", 100, 50)
+	l.AddDetections([]vision.DetResult{{ClassID: 2, ClassName: "car", Box: image.Rect(1, 2, 30, 40)}})
+	l.AddOBBs([]vision.OBBResult{{ClassID: 0, Corners: [4]image.Point{{10, 0}, {20, 10}, {10, 20}, {0, 10}}}})
+
+	kpts := make([]vision.KeyPoint, 17)
+	kpts[5] = vision.KeyPoint{X: 7, Y: 8, Score: 0.9}
+	poses := []vision.PoseResult{
+		{ClassName: "person", Box: image.Rect(0, 0, 10, 10), KeyPoints: kpts},
+		{ClassName: "person", Box: image.Rect(20, 0, 30, 10), KeyPoints: kpts},
+	}
+	l.AddPoses(poses, DefaultKeyPointThreshold)
+
+	var buf bytes.Buffer
+	if err := l.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadLabelMe(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, l) {
+		t.Fatalf("读回的标注与写入的不一致:\n%+v\n%+v", got, l)
+	}
+
+	// 检测框、旋转框、每个姿态实例一个检测框和一个可见关键点
+	if len(got.Shapes) != 6 {
+		t.Fatalf("应有 6 个形状，实际 %d", len(got.Shapes))
+	}
+	if s := got.Shapes[0]; s.ShapeType != "rectangle" || s.Label != "car" || s.Points[1] != [2]float64{30, 40} {
+		t.Errorf("检测框 %+v", s)
+	}
+	if s := got.Shapes[1]; s.ShapeType != "polygon" || s.Label != "0" || len(s.Points) != 4 {
+		t.Errorf("旋转框 %+v", s)
+	}
+	if s := got.Shapes[3]; s.ShapeType != "point" || s.Label != "left_shoulder" || *s.GroupID != 0 {
+		t.Errorf("关键点 %+v", s)
+	}
+	if s := got.Shapes[5]; *s.GroupID != 1 {
+		t.Errorf("第二个实例的 group_id 应为 1，实际 %d", *s.GroupID)
+	}
+}
+
+func TestLabelMe_Segmentations(t *testing.T) {
+	// 两个连通域转为两个 polygon
+	mask := image.NewGray(image.Rect(0, 0, 20, 10))
+	for _, r := range []image.Rectangle{image.Rect(1, 1, 4, 4), image.Rect(10, 2, 15, 8)} {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				mask.Pix[mask.PixOffset(x, y)] = 255
+			}
+		}
+	}
+	l := NewLabelMe("test.png", 20, 10)
+	l.AddSegmentations([]vision.SegResult{{ClassName: "cat", Mask: mask, MaskSize: image.Pt(20, 10)}}, vision.ContourOptions{})
+	if len(l.Shapes) != 2 || l.Shapes[0].Label != "cat" || l.Shapes[1].ShapeType != "polygon" {
+		t.Fatalf("形状 %+v", l.Shapes)
+	}
+	if pts := l.Shapes[0].Points; !reflect.DeepEqual(pts, [][2]float64{{4, 1}, {4, 4}, {1, 4}, {1, 1}}) {
+		t.Errorf("顶点 %v", pts)
+	}
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"io"
+)
+
+// VOCAnnotation Pascal VOC 格式的标注，每张图片一个 XML 文件
+type VOCAnnotation struct {
+	XMLName   xml.Name    `xml:"annotation"`
+	Folder    string      `xml:"folder"`
+	Filename  string      `xml:"filename"`
+	Size      VOCSize     `xml:"size"`
+	Segmented int         `xml:"segmented"`
+	Objects   []VOCObject `xml:"object"`
+}
+
+// VOCSize 图片尺寸
+type VOCSize struct {
+	Width  int `xml:"width"`
+	Height int `xml:"height"`
+	Depth  int `xml:"depth"`
+}
+
+// VOCObject 单个目标的标注
+type VOCObject struct {
+	Name      string `xml:"name"`
+	Pose      string `xml:"pose"`
+	Truncated int    `xml:"truncated"` // 目标是否被图片边缘截断
+	Difficult int    `xml:"difficult"`
+	BndBox    VOCBox `xml:"bndbox"`
+}
+
+// VOCBox 检测框，坐标从 1 开始且包含 xmax、ymax
+type VOCBox struct {
+	XMin int `xml:"xmin"`
+	YMin int `xml:"ymin"`
+	XMax int `xml:"xmax"`
+	YMax int `xml:"ymax"`
+}
+
+// Box 检测框，坐标从 0 开始且不包含 Max，与检测结果的 Box 一致
+func (o VOCObject) Box() image.Rectangle {
+	return image.Rect(o.BndBox.XMin-1, o.BndBox.YMin-1, o.BndBox.XMax, o.BndBox.YMax)
+}
+
+// NewVOC 根据目标检测结果创建 VOC 标注
+//
+// # Params:
+//
+//	fileName: 图片文件名
+//	width, height: 图片尺寸
+//	results: 检测结果，类别名称为空时使用类别 ID
+func NewVOC(fileName string, width, height int, results []vision.DetResult) *VOCAnnotation {
+	a := &VOCAnnotation{
+		Filename: fileName,
+		Size:     VOCSize{Width: width, Height: height, Depth: 3},
+	}
+	bounds := image.Rect(0, 0, width, height)
+	for _, res := range results {
+		box := res.Box.Intersect(bounds)
+		truncated := 0
+		if box != res.Box || box.Min.X == 0 || box.Min.Y == 0 || box.Max.X == width || box.Max.Y == height {
+			truncated = 1
+		}
+		a.Objects = append(a.Objects, VOCObject{
+			Name:      labelName(res.ClassID, res.ClassName),
+			Pose:      "Unspecified",
+			Truncated: truncated,
+			BndBox:    VOCBox{XMin: box.Min.X + 1, YMin: box.Min.Y + 1, XMax: box.Max.X, YMax: box.Max.Y},
+		})
+	}
+	return a
+}
+
+// ReadVOC 读取 VOC 格式的 XML
+func ReadVOC(r io.Reader) (*VOCAnnotation, error) {
+	a := new(VOCAnnotation)
+	if err := xml.NewDecoder(r).Decode(a); err != nil {
+		return nil, fmt.Errorf("解析 VOC XML 失败: %w", err)
+	}
+	return a, nil
+}
+
+// Write 写入 VOC 格式的 XML
+func (a *VOCAnnotation) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(a); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// DetResults 转为目标检测结果，类别 ID 为名称在 names 中的索引，不存在时为 -1
+func (a *VOCAnnotation) DetResults(names []string) []vision.DetResult {
+	results := make([]vision.DetResult, len(a.Objects))
+	for i, o := range a.Objects {
+		classID := -1
+		for j, name := range names {
+			if name == o.Name {
+				classID = j
+				break
+			}
+		}
+		results[i] = vision.DetResult{ClassID: classID, ClassName: o.Name, Score: 1, Box: o.Box()}
+	}
+	return results
+}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"bytes"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVOC_RoundTrip(t *testing.T) {
+	names := []string{"person", "car"}
+	results := []vision.DetResult{
+		{ClassID: 1, ClassName: "car", Score: 1, Box: image.Rect(10, 20, 110, 60)},
+		{ClassID: 0, ClassName: "person", Score: 1, Box: image.Rect(0, 5, 30, 80)},
+	}
+	var buf bytes.Buffer
+	if err := NewVOC("test.jpg", 640, 480, results).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<xmin>11</xmin>") {
+		t.Errorf("VOC 坐标应从 1 开始:\n%s", buf.String())
+	}
+
+	a, err := ReadVOC(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Filename != "test.jpg" || a.Size != (VOCSize{Width: 640, Height: 480, Depth: 3}) {
+		t.Errorf("图片信息 %+v", a)
+	}
+	if a.Objects[0].Truncated != 0 || a.Objects[1].Truncated != 1 {
+		t.Errorf("截断标记 %d, %d，预期 0, 1", a.Objects[0].Truncated, a.Objects[1].Truncated)
+	}
+	if got := a.DetResults(names); !reflect.DeepEqual(got, results) {
+		t.Errorf("读回的结果 %+v，预期 %+v", got, results)
+	}
+}
@@ -0,0 +1,192 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// YOLOLabel YOLO txt 标注中的一行，坐标为归一化到 [0, 1] 的值
+//
+//   - 检测: cx cy w h
+//   - 分割: x1 y1 x2 y2 ...
+//   - 姿态: cx cy w h px1 py1 v1 px2 py2 v2 ...
+//   - 旋转框: x1 y1 x2 y2 x3 y3 x4 y4
+type YOLOLabel struct {
+	ClassID int
+	Values  []float64
+}
+
+// Box 检测框，适用于检测、姿态标注
+func (l YOLOLabel) Box(width, height int) image.Rectangle {
+	if len(l.Values) < 4 {
+		return image.Rectangle{}
+	}
+	cx, cy := l.Values[0]*float64(width), l.Values[1]*float64(height)
+	w, h := l.Values[2]*float64(width), l.Values[3]*float64(height)
+	return image.Rect(round(cx-w/2), round(cy-h/2), round(cx+w/2), round(cy+h/2))
+}
+
+// Points 多边形顶点，适用于分割、旋转框标注
+func (l YOLOLabel) Points(width, height int) []image.Point {
+	pts := make([]image.Point, len(l.Values)/2)
+	for i := range pts {
+		pts[i] = image.Pt(round(l.Values[i*2]*float64(width)), round(l.Values[i*2+1]*float64(height)))
+	}
+	return pts
+}
+
+// KeyPoints 关键点，适用于姿态标注，可见 (v=2) 的关键点 Score 为 1，被遮挡 (v=1) 的为 0.5，未标注的为 0
+func (l YOLOLabel) KeyPoints(width, height int) []vision.KeyPoint {
+	if len(l.Values) < 4 {
+		return nil
+	}
+	raw := l.Values[4:]
+	kpts := make([]vision.KeyPoint, len(raw)/3)
+	for i := range kpts {
+		kpts[i] = vision.KeyPoint{
+			X:     round(raw[i*3] * float64(width)),
+			Y:     round(raw[i*3+1] * float64(height)),
+			Score: float32(raw[i*3+2]) / 2,
+		}
+	}
+	return kpts
+}
+
+// ReadYOLO 读取 YOLO txt 标注，忽略空行
+func ReadYOLO(r io.Reader) ([]YOLOLabel, error) {
+	var labels []YOLOLabel
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		classID, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行类别 ID 格式错误: %w", line, err)
+		}
+		label := YOLOLabel{ClassID: classID, Values: make([]float64, len(fields)-1)}
+		for i, f := range fields[1:] {
+			if label.Values[i], err = strconv.ParseFloat(f, 64); err != nil {
+				return nil, fmt.Errorf("第 %d 行坐标格式错误: %w", line, err)
+			}
+		}
+		labels = append(labels, label)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 YOLO 标注失败: %w", err)
+	}
+	return labels, nil
+}
+
+// WriteYOLO 写入 YOLO txt 标注，每个标注一行
+func WriteYOLO(w io.Writer, labels []YOLOLabel) error {
+	bw := bufio.NewWriter(w)
+	for _, l := range labels {
+		bw.WriteString(strconv.Itoa(l.ClassID))
+		for _, v := range l.Values {
+			bw.WriteByte(' ')
+			bw.WriteString(strconv.FormatFloat(v, 'f', 6, 64))
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// WriteYOLODet 写入目标检测结果
+//
+// # Params:
+//
+//	w: 输出，例如与图片同名的 .txt 文件
+//	results: 检测结果
+//	width, height: 图片尺寸
+func WriteYOLODet(w io.Writer, results []vision.DetResult, width, height int) error {
+	labels := make([]YOLOLabel, len(results))
+	for i, res := range results {
+		labels[i] = YOLOLabel{ClassID: res.ClassID, Values: yoloBox(res.Box, width, height)}
+	}
+	return WriteYOLO(w, labels)
+}
+
+// WriteYOLOSeg 写入分割结果，每个实例只保留面积最大的连通域的外轮廓，坐标按 MaskSize 归一化
+//
+// # Params:
+//
+//	w: 输出
+//	results: 分割结果
+//	tolerance: 轮廓简化的容差 (像素)，<= 0 时不简化
+func WriteYOLOSeg(w io.Writer, results []vision.SegResult, tolerance float64) error {
+	var labels []YOLOLabel
+	for _, res := range results {
+		polys := res.Polygons(vision.ContourOptions{Tolerance: tolerance, LargestOnly: true})
+		if len(polys) == 0 {
+			continue
+		}
+		labels = append(labels, YOLOLabel{ClassID: res.ClassID, Values: yoloPoints(polys[0].Outer, res.MaskSize.X, res.MaskSize.Y)})
+	}
+	return WriteYOLO(w, labels)
+}
+
+// WriteYOLOPose 写入姿态估计结果，置信度低于 threshold 的关键点标记为未标注 (0 0 0)
+//
+// # Params:
+//
+//	w: 输出
+//	results: 姿态估计结果
+//	width, height: 图片尺寸，用于坐标归一化
+//	threshold: 关键点置信度阈值，例如 DefaultKeyPointThreshold
+func WriteYOLOPose(w io.Writer, results []vision.PoseResult, width, height int, threshold float32) error {
+	labels := make([]YOLOLabel, len(results))
+	for i, res := range results {
+		values := yoloBox(res.Box, width, height)
+		for _, kp := range res.KeyPoints {
+			if kp.Score >= threshold {
+				values = append(values, float64(kp.X)/float64(width), float64(kp.Y)/float64(height), 2)
+			} else {
+				values = append(values, 0, 0, 0)
+			}
+		}
+		labels[i] = YOLOLabel{ClassID: res.ClassID, Values: values}
+	}
+	return WriteYOLO(w, labels)
+}
+
+// WriteYOLOOBB 写入旋转目标检测结果，每行为旋转框的 4 个顶点
+func WriteYOLOOBB(w io.Writer, results []vision.OBBResult, width, height int) error {
+	labels := make([]YOLOLabel, len(results))
+	for i, res := range results {
+		labels[i] = YOLOLabel{ClassID: res.ClassID, Values: yoloPoints(res.Corners[:], width, height)}
+	}
+	return WriteYOLO(w, labels)
+}
+
+// yoloBox 矩形转为归一化的 cx, cy, w, h
+func yoloBox(r image.Rectangle, width, height int) []float64 {
+	fw, fh := float64(width), float64(height)
+	return []float64{
+		(float64(r.Min.X+r.Max.X) / 2) / fw,
+		(float64(r.Min.Y+r.Max.Y) / 2) / fh,
+		float64(r.Dx()) / fw,
+		float64(r.Dy()) / fh,
+	}
+}
+
+// yoloPoints 顶点转为归一化的 x1, y1, x2, y2, ...
+func yoloPoints(pts []image.Point, width, height int) []float64 {
+	values := make([]float64, 0, len(pts)*2)
+	for _, p := range pts {
+		values = append(values, float64(p.X)/float64(width), float64(p.Y)/float64(height))
+	}
+	return values
+}
+
+// round 四舍五入取整
+func round(v float64) int {
+	return int(math.Round(v))
+}
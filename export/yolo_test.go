@@ -0,0 +1,99 @@
+package export
+
+import (
+	"bytes"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestYOLODet_RoundTrip(t *testing.T) {
+	results := []vision.DetResult{
+		{ClassID: 3, Box: image.Rect(10, 20, 110, 60)},
+		{ClassID: 0, Box: image.Rect(0, 0, 640, 480)},
+	}
+	var buf bytes.Buffer
+	if err := WriteYOLODet(&buf, results, 640, 480); err != nil {
+		t.Fatal(err)
+	}
+	if line := strings.Split(buf.String(), "\n")[0]; line != "3 0.093750 0.083333 0.156250 0.083333" {
+		t.Errorf("输出 %q", line)
+	}
+
+	labels, err := ReadYOLO(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, l := range labels {
+		if l.ClassID != results[i].ClassID || l.Box(640, 480) != results[i].Box {
+			t.Errorf("第 %d 个标注 %+v，预期 %+v", i, l, results[i])
+		}
+	}
+}
+
+func TestYOLOSeg_RoundTrip(t *testing.T) {
+	mask := image.NewGray(image.Rect(0, 0, 100, 50))
+	for y := 10; y < 20; y++ {
+		for x := 30; x < 60; x++ {
+			mask.Pix[mask.PixOffset(x, y)] = 255
+		}
+	}
+	var buf bytes.Buffer
+	if err := WriteYOLOSeg(&buf, []vision.SegResult{{ClassID: 1, Mask: mask, MaskSize: image.Pt(100, 50)}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	labels, err := ReadYOLO(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []image.Point{{60, 10}, {60, 20}, {30, 20}, {30, 10}}
+	if len(labels) != 1 || !reflect.DeepEqual(labels[0].Points(100, 50), want) {
+		t.Fatalf("标注 %+v，预期顶点 %v", labels, want)
+	}
+}
+
+func TestYOLOPose_RoundTrip(t *testing.T) {
+	res := vision.PoseResult{
+		ClassID:   0,
+		Box:       image.Rect(10, 10, 50, 90),
+		KeyPoints: []vision.KeyPoint{{X: 20, Y: 30, Score: 0.9}, {X: 40, Y: 50, Score: 0.1}},
+	}
+	var buf bytes.Buffer
+	if err := WriteYOLOPose(&buf, []vision.PoseResult{res}, 100, 100, DefaultKeyPointThreshold); err != nil {
+		t.Fatal(err)
+	}
+	labels, err := ReadYOLO(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 1 || labels[0].Box(100, 100) != res.Box {
+		t.Fatalf("标注 %+v", labels)
+	}
+	want := []vision.KeyPoint{{X: 20, Y: 30, Score: 1}, {}}
+	if got := labels[0].KeyPoints(100, 100); !reflect.DeepEqual(got, want) {
+		t.Errorf("关键点 %v，预期 %v", got, want)
+	}
+}
+
+func TestYOLOOBB_RoundTrip(t *testing.T) {
+	res := vision.OBBResult{ClassID: 2, Corners: [4]image.Point{{10, 0}, {20, 10}, {10, 20}, {0, 10}}}
+	var buf bytes.Buffer
+	if err := WriteYOLOOBB(&buf, []vision.OBBResult{res}, 200, 100); err != nil {
+		t.Fatal(err)
+	}
+	labels, err := ReadYOLO(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 1 || labels[0].ClassID != 2 || !reflect.DeepEqual(labels[0].Points(200, 100), res.Corners[:]) {
+		t.Fatalf("标注 %+v", labels)
+	}
+}
+
+func TestReadYOLO_Error(t *testing.T) {
+	if _, err := ReadYOLO(strings.NewReader("0 0.5 x 0.1 0.1\n")); err == nil {
+		t.Error("非法坐标应返回错误")
+	}
+}
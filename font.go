@@ -0,0 +1,71 @@
+package vision
+
+import (
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"image"
+)
+
+// fallbackFace 按顺序从多个字体中查找字形，主字体缺少的字形 (例如中文) 使用回退字体绘制
+//
+// 与 font.Face 一样不支持并发使用
+type fallbackFace struct {
+	fonts []*opentype.Font
+	faces []font.Face
+	buf   sfnt.Buffer
+}
+
+// face 包含字形 r 的第一个字体，都不包含时使用主字体 (绘制 .notdef)
+func (f *fallbackFace) face(r rune) int {
+	for i, ft := range f.fonts {
+		if idx, err := ft.GlyphIndex(&f.buf, r); err == nil && idx != 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+func (f *fallbackFace) Close() error {
+	var firstErr error
+	for _, face := range f.faces {
+		if err := face.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.faces[f.face(r)].Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.faces[f.face(r)].GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.faces[f.face(r)].GlyphAdvance(r)
+}
+
+// Kern 两个字形来自不同字体时不做字距调整
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	i := f.face(r0)
+	if i != f.face(r1) {
+		return 0
+	}
+	return f.faces[i].Kern(r0, r1)
+}
+
+// Metrics 以主字体为准，行高、ascent 和 descent 取所有字体的最大值，保证混排时不会重叠
+func (f *fallbackFace) Metrics() font.Metrics {
+	m := f.faces[0].Metrics()
+	for _, face := range f.faces[1:] {
+		fm := face.Metrics()
+		m.Height = max(m.Height, fm.Height)
+		m.Ascent = max(m.Ascent, fm.Ascent)
+		m.Descent = max(m.Descent, fm.Descent)
+	}
+	return m
+}
@@ -0,0 +1,33 @@
+// Package fonts 内嵌字体，用于无需字体文件时绘制中文
+//
+// 内嵌字体约 1MB，单独放在子包中，只有导入本包时才会编译进程序
+package fonts
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/getcharzp/go-vision"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Unifont GNU Unifont 的子集，包含 ASCII、Latin-1 及 GB2312 一级汉字和符号
+//
+// 许可证见 LICENSE-unifont.txt (SIL Open Font License 1.1)
+//
+//go:embed unifont-sc.ttf
+var Unifont []byte
+
+// NewDefaultTextDrawer 使用内嵌字体创建文本绘制工具，无需字体文件
+//
+// 主字体为 Go 字体，缺少的字形 (例如中文) 使用 Unifont 绘制
+func NewDefaultTextDrawer() (*vision.TextDrawer, error) {
+	d, err := vision.NewTextDrawerFromBytes(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.AddFallback(Unifont); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("加载内嵌字体失败：%w", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,33 @@
+package fonts
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestNewDefaultTextDrawer(t *testing.T) {
+	d, err := NewDefaultTextDrawer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	render := func(text string) *image.Gray {
+		img := image.NewGray(image.Rect(0, 0, 100, 40))
+		d.DrawText(img, text, 5, 28, color.White)
+		return img
+	}
+	if bytes.Count(render("Hello").Pix, []byte{0}) == 100*40 {
+		t.Error("默认字体应能绘制拉丁字母")
+	}
+	// 缺少字形时绘制的 .notdef 方框都相同，不同的中文字形说明使用了回退字体
+	zhong, wen := render("中"), render("文")
+	if bytes.Count(zhong.Pix, []byte{0}) == 100*40 {
+		t.Error("默认字体应能绘制中文")
+	}
+	if bytes.Equal(zhong.Pix, wen.Pix) {
+		t.Error("不同的中文应绘制为不同的字形")
+	}
+}
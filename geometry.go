@@ -0,0 +1,167 @@
+package vision
+
+import (
+	"image"
+	"math"
+)
+
+// RotatedBox 旋转矩形
+type RotatedBox struct {
+	CX, CY float32 // 中心点坐标
+	W, H   float32 // 宽高
+	Angle  float32 // 旋转角度 (弧度)
+}
+
+// Corners 计算旋转矩形的 4 个角点：TopLeft, TopRight, BottomRight, BottomLeft
+func (b RotatedBox) Corners() [4][2]float32 {
+	cosA := float32(math.Cos(float64(b.Angle)))
+	sinA := float32(math.Sin(float64(b.Angle)))
+
+	dx := [4]float32{-b.W / 2, b.W / 2, b.W / 2, -b.W / 2}
+	dy := [4]float32{-b.H / 2, -b.H / 2, b.H / 2, b.H / 2}
+
+	var corners [4][2]float32
+	for i := 0; i < 4; i++ {
+		corners[i][0] = b.CX + dx[i]*cosA - dy[i]*sinA
+		corners[i][1] = b.CY + dx[i]*sinA + dy[i]*cosA
+	}
+	return corners
+}
+
+// Area 旋转矩形面积
+func (b RotatedBox) Area() float32 {
+	return b.W * b.H
+}
+
+// RotatedIOU 计算两个旋转矩形的交并比
+//
+// 通过凸多边形裁剪求出精确的相交区域，而不是使用外接矩形近似
+//
+// # Params:
+//
+//	a, b: 旋转矩形
+func RotatedIOU(a, b RotatedBox) float32 {
+	ca, cb := a.Corners(), b.Corners()
+	pa := make([][2]float64, 4)
+	pb := make([][2]float64, 4)
+	for i := 0; i < 4; i++ {
+		pa[i] = [2]float64{float64(ca[i][0]), float64(ca[i][1])}
+		pb[i] = [2]float64{float64(cb[i][0]), float64(cb[i][1])}
+	}
+	return convexIOU(pa, pb)
+}
+
+// PolygonIOU 计算两个凸多边形的交并比，顶点顺序可以是顺时针或逆时针
+//
+// 可直接用于 OBB 结果的角点，例如跨图块对旋转框去重
+//
+// # Params:
+//
+//	a, b: 凸多边形的顶点
+func PolygonIOU(a, b []image.Point) float32 {
+	pa := make([][2]float64, len(a))
+	for i, p := range a {
+		pa[i] = [2]float64{float64(p.X), float64(p.Y)}
+	}
+	pb := make([][2]float64, len(b))
+	for i, p := range b {
+		pb[i] = [2]float64{float64(p.X), float64(p.Y)}
+	}
+	return convexIOU(pa, pb)
+}
+
+// convexIOU 计算两个凸多边形的交并比
+func convexIOU(a, b [][2]float64) float32 {
+	if len(a) < 3 || len(b) < 3 {
+		return 0
+	}
+	a, b = toCCW(a), toCCW(b)
+	areaA := polygonArea(a)
+	areaB := polygonArea(b)
+	if areaA <= 0 || areaB <= 0 {
+		return 0
+	}
+
+	inter := polygonArea(clipPolygon(a, b))
+	union := areaA + areaB - inter
+	if union <= 0 {
+		return 0
+	}
+	return float32(inter / union)
+}
+
+// clipPolygon 使用 Sutherland-Hodgman 算法，求凸多边形 subject 被凸多边形 clip 裁剪后的区域
+//
+// 两个多边形的顶点都需要是逆时针顺序
+func clipPolygon(subject, clip [][2]float64) [][2]float64 {
+	output := subject
+	for i := 0; i < len(clip) && len(output) > 0; i++ {
+		e1 := clip[i]
+		e2 := clip[(i+1)%len(clip)]
+
+		input := output
+		output = make([][2]float64, 0, len(input)+1)
+		for j := 0; j < len(input); j++ {
+			cur := input[j]
+			prev := input[(j+len(input)-1)%len(input)]
+			curIn := cross(e1, e2, cur) >= 0
+			prevIn := cross(e1, e2, prev) >= 0
+
+			if curIn {
+				if !prevIn {
+					output = append(output, lineIntersect(prev, cur, e1, e2))
+				}
+				output = append(output, cur)
+			} else if prevIn {
+				output = append(output, lineIntersect(prev, cur, e1, e2))
+			}
+		}
+	}
+	return output
+}
+
+// cross 计算向量 (b - a) 与 (p - a) 的叉积，>0 表示 p 在 ab 左侧
+func cross(a, b, p [2]float64) float64 {
+	return (b[0]-a[0])*(p[1]-a[1]) - (b[1]-a[1])*(p[0]-a[0])
+}
+
+// lineIntersect 计算线段 pq 与直线 ab 的交点
+func lineIntersect(p, q, a, b [2]float64) [2]float64 {
+	cp := cross(a, b, p)
+	cq := cross(a, b, q)
+	if cp == cq {
+		return q
+	}
+	t := cp / (cp - cq)
+	return [2]float64{p[0] + t*(q[0]-p[0]), p[1] + t*(q[1]-p[1])}
+}
+
+// signedArea 多边形的有向面积，逆时针为正
+func signedArea(poly [][2]float64) float64 {
+	area := 0.0
+	for i := range poly {
+		j := (i + 1) % len(poly)
+		area += poly[i][0]*poly[j][1] - poly[j][0]*poly[i][1]
+	}
+	return area / 2
+}
+
+// polygonArea 多边形面积
+func polygonArea(poly [][2]float64) float64 {
+	if len(poly) < 3 {
+		return 0
+	}
+	return math.Abs(signedArea(poly))
+}
+
+// toCCW 将多边形的顶点调整为逆时针顺序
+func toCCW(poly [][2]float64) [][2]float64 {
+	if signedArea(poly) >= 0 {
+		return poly
+	}
+	reversed := make([][2]float64, len(poly))
+	for i, p := range poly {
+		reversed[len(poly)-1-i] = p
+	}
+	return reversed
+}
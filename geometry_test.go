@@ -0,0 +1,53 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestRotatedIOU(t *testing.T) {
+	square := RotatedBox{CX: 0, CY: 0, W: 2, H: 2}
+
+	if iou := RotatedIOU(square, square); math.Abs(float64(iou)-1) > 1e-5 {
+		t.Fatalf("相同旋转框的 IOU 应为 1，实际 %f", iou)
+	}
+
+	// 旋转 45° 后与原正方形的交集为正八边形，面积 8(√2-1)
+	rotated := square
+	rotated.Angle = math.Pi / 4
+	inter := 8 * (math.Sqrt2 - 1)
+	want := inter / (8 - inter)
+	if iou := RotatedIOU(square, rotated); math.Abs(float64(iou)-want) > 1e-4 {
+		t.Fatalf("旋转 45° 的 IOU 应为 %f，实际 %f", want, iou)
+	}
+
+	far := RotatedBox{CX: 10, CY: 10, W: 2, H: 2, Angle: 0.3}
+	if iou := RotatedIOU(square, far); iou != 0 {
+		t.Fatalf("不相交旋转框的 IOU 应为 0，实际 %f", iou)
+	}
+}
+
+func TestRotatedIOU_DiagonalNeighbours(t *testing.T) {
+	// 两艘斜向并排停靠的船，外接矩形高度重叠，但旋转框本身并不相交
+	a := RotatedBox{CX: 100, CY: 100, W: 200, H: 20, Angle: math.Pi / 4}
+	b := RotatedBox{CX: 100 + 25/math.Sqrt2, CY: 100 - 25/math.Sqrt2, W: 200, H: 20, Angle: math.Pi / 4}
+
+	if iou := RotatedIOU(a, b); iou > 1e-4 {
+		t.Fatalf("并排旋转框的 IOU 应为 0，实际 %f", iou)
+	}
+}
+
+func TestPolygonIOU(t *testing.T) {
+	a := []image.Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	// 顺时针顺序同样支持
+	b := []image.Point{{5, 10}, {15, 10}, {15, 0}, {5, 0}}
+
+	want := 50.0 / 150.0
+	if iou := PolygonIOU(a, b); math.Abs(float64(iou)-want) > 1e-5 {
+		t.Fatalf("IOU 应为 %f，实际 %f", want, iou)
+	}
+	if iou := PolygonIOU(a, a[:2]); iou != 0 {
+		t.Fatalf("退化多边形的 IOU 应为 0，实际 %f", iou)
+	}
+}
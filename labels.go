@@ -0,0 +1,57 @@
+package vision
+
+// COCOClasses COCO 数据集的 80 个类别名称，YOLO 检测、分割模型的默认类别
+//
+// 参考：https://github.com/ultralytics/ultralytics/blob/main/ultralytics/cfg/datasets/coco.yaml
+var COCOClasses = []string{
+	"person", "bicycle", "car", "motorcycle", "airplane", "bus", "train", "truck", "boat", "traffic light",
+	"fire hydrant", "stop sign", "parking meter", "bench", "bird", "cat", "dog", "horse", "sheep", "cow",
+	"elephant", "bear", "zebra", "giraffe", "backpack", "umbrella", "handbag", "tie", "suitcase", "frisbee",
+	"skis", "snowboard", "sports ball", "kite", "baseball bat", "baseball glove", "skateboard", "surfboard", "tennis racket", "bottle",
+	"wine glass", "cup", "fork", "knife", "spoon", "bowl", "banana", "apple", "sandwich", "orange",
+	"broccoli", "carrot", "hot dog", "pizza", "donut", "cake", "chair", "couch", "potted plant", "bed",
+	"dining table", "toilet", "tv", "laptop", "mouse", "remote", "keyboard", "cell phone", "microwave", "oven",
+	"toaster", "sink", "refrigerator", "book", "clock", "vase", "scissors", "teddy bear", "hair drier", "toothbrush",
+}
+
+// COCOPoseClasses COCO-Pose 数据集的类别名称，YOLO 姿态模型的默认类别
+//
+// 参考：https://github.com/ultralytics/ultralytics/blob/main/ultralytics/cfg/datasets/coco-pose.yaml
+var COCOPoseClasses = []string{"person"}
+
+// COCOKeyPoints COCO-Pose 数据集的 17 个关键点名称，顺序与姿态模型输出的关键点一致
+var COCOKeyPoints = []string{
+	"nose", "left_eye", "right_eye", "left_ear", "right_ear",
+	"left_shoulder", "right_shoulder", "left_elbow", "right_elbow", "left_wrist", "right_wrist",
+	"left_hip", "right_hip", "left_knee", "right_knee", "left_ankle", "right_ankle",
+}
+
+// COCOSkeleton COCO-Pose 关键点之间的骨架连接，索引从 0 开始
+var COCOSkeleton = [][2]int{
+	{15, 13}, {13, 11}, {16, 14}, {14, 12}, // 腿
+	{11, 12}, {5, 11}, {6, 12}, // 躯干
+	{5, 6}, {5, 7}, {6, 8}, {7, 9}, {8, 10}, // 臂/肩
+	{1, 2}, {0, 1}, {0, 2}, {1, 3}, {2, 4}, // 面部
+}
+
+// DOTAClasses DOTA-v1 数据集的 15 个类别名称，YOLO OBB 模型的默认类别
+//
+// 参考：https://github.com/ultralytics/ultralytics/blob/main/ultralytics/cfg/datasets/DOTAv1.yaml
+var DOTAClasses = []string{
+	"plane", "ship", "storage tank", "baseball diamond", "tennis court",
+	"basketball court", "ground track field", "harbor", "bridge", "large vehicle",
+	"small vehicle", "helicopter", "roundabout", "soccer ball field", "swimming pool",
+}
+
+// ClassName 根据类别 ID 获取类别名称，越界时返回空字符串
+//
+// # Params:
+//
+//	names: 类别名称表，例如 COCOClasses
+//	classID: 类别 ID
+func ClassName(names []string, classID int) string {
+	if classID < 0 || classID >= len(names) {
+		return ""
+	}
+	return names[classID]
+}
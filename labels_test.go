@@ -0,0 +1,33 @@
+package vision
+
+import "testing"
+
+func TestClassTables(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		size  int
+		ids   map[int]string
+	}{
+		{"COCO", COCOClasses, 80, map[int]string{0: "person", 2: "car", 79: "toothbrush"}},
+		{"DOTA", DOTAClasses, 15, map[int]string{0: "plane", 14: "swimming pool"}},
+		{"ImageNet", ImageNetClasses, 1000, map[int]string{0: "tench", 436: "station wagon", 656: "minivan", 999: "toilet paper"}},
+	}
+	for _, tt := range tests {
+		if len(tt.names) != tt.size {
+			t.Errorf("%s 类别数应为 %d，实际 %d", tt.name, tt.size, len(tt.names))
+		}
+		for id, want := range tt.ids {
+			if got := ClassName(tt.names, id); got != want {
+				t.Errorf("%s[%d] 应为 %q，实际 %q", tt.name, id, want, got)
+			}
+		}
+	}
+
+	if got := ClassName(COCOClasses, -1); got != "" {
+		t.Errorf("越界时应返回空字符串，实际 %q", got)
+	}
+	if got := ClassName(nil, 0); got != "" {
+		t.Errorf("类别表为空时应返回空字符串，实际 %q", got)
+	}
+}
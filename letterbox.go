@@ -0,0 +1,162 @@
+package vision
+
+import (
+	"github.com/up-zero/gotool/imageutil"
+	"image"
+	"image/draw"
+	"math"
+)
+
+// LetterboxMode 预处理时缩放后的图片在模型输入中的放置方式
+type LetterboxMode int
+
+const (
+	LetterboxTopLeft LetterboxMode = iota // 左上角对齐，仅在右侧和下方填充
+	LetterboxCenter                       // 居中对齐，四周对称填充 (与 Ultralytics 训练时一致)
+)
+
+// Padding 计算缩放后的图片在模型输入中的偏移量
+//
+// # Params:
+//
+//	inputSize: 模型输入尺寸
+//	newW, newH: 缩放后的图片尺寸
+func (m LetterboxMode) Padding(inputSize, newW, newH int) (padX, padY int) {
+	if m != LetterboxCenter {
+		return 0, 0
+	}
+	return (inputSize - newW) / 2, (inputSize - newH) / 2
+}
+
+// LetterboxParams 图片等比缩放到模型输入时的尺寸信息
+type LetterboxParams struct {
+	OrigW, OrigH int
+	Scale        float32
+	PadX, PadY   int // 填充偏移量 (模型输入尺度)
+}
+
+// OrigX 将模型输入尺度的 x 坐标映射回原图
+func (p LetterboxParams) OrigX(x float32) float32 {
+	return (x - float32(p.PadX)) / p.Scale
+}
+
+// OrigY 将模型输入尺度的 y 坐标映射回原图
+func (p LetterboxParams) OrigY(y float32) float32 {
+	return (y - float32(p.PadY)) / p.Scale
+}
+
+// InputX 将原图的 x 坐标映射到模型输入尺度
+func (p LetterboxParams) InputX(x float32) float32 {
+	return x*p.Scale + float32(p.PadX)
+}
+
+// InputY 将原图的 y 坐标映射到模型输入尺度
+func (p LetterboxParams) InputY(y float32) float32 {
+	return y*p.Scale + float32(p.PadY)
+}
+
+// MaskLayout 计算指定分辨率下 Mask 的尺寸、检测框，以及 Mask 坐标到原型坐标的映射
+//
+// # Params:
+//
+//	res: Mask 分辨率
+//	box: 原图上的检测框
+//	stride: 模型输入到原型的步长，例如 640/160 = 4
+func (p LetterboxParams) MaskLayout(res MaskResolution, box image.Rectangle, stride float32) (image.Point, image.Rectangle, MaskTransform) {
+	t := MaskTransform{OffsetX: float32(p.PadX) / stride, OffsetY: float32(p.PadY) / stride}
+	switch res {
+	case MaskInput:
+		t.Scale = 1 / stride
+		size := image.Pt(int(float32(p.OrigW)*p.Scale), int(float32(p.OrigH)*p.Scale))
+		return size, scaleRect(box, p.Scale), t
+	case MaskProto:
+		t.Scale = 1
+		s := p.Scale / stride
+		size := image.Pt(int(math.Ceil(float64(float32(p.OrigW)*s))), int(math.Ceil(float64(float32(p.OrigH)*s))))
+		return size, scaleRect(box, s), t
+	default:
+		t.Scale = p.Scale / stride
+		return image.Pt(p.OrigW, p.OrigH), box, t
+	}
+}
+
+// scaleRect 缩放矩形，结果向外取整
+func scaleRect(r image.Rectangle, s float32) image.Rectangle {
+	return image.Rect(
+		int(math.Floor(float64(float32(r.Min.X)*s))),
+		int(math.Floor(float64(float32(r.Min.Y)*s))),
+		int(math.Ceil(float64(float32(r.Max.X)*s))),
+		int(math.Ceil(float64(float32(r.Max.Y)*s))),
+	)
+}
+
+// Letterbox 将图片等比缩放后写入单张图片的输入数据 (CHW + Normalize 0-1)
+//
+// 未被图片覆盖的区域保持 dst 中原有的数值
+//
+// # Params:
+//
+//	dst: 单张图片的输入数据，长度为 3*inputSize*inputSize
+//	img: 原图
+//	inputSize: 模型输入尺寸
+//	mode: 放置方式
+func Letterbox(dst []float32, img image.Image, inputSize int, mode LetterboxMode) LetterboxParams {
+	bounds := img.Bounds()
+	params := LetterboxParams{
+		OrigW: bounds.Dx(),
+		OrigH: bounds.Dy(),
+	}
+
+	scale := float32(inputSize) / float32(max(params.OrigW, params.OrigH))
+	params.Scale = scale
+
+	newW := int(float32(params.OrigW) * scale)
+	newH := int(float32(params.OrigH) * scale)
+	params.PadX, params.PadY = mode.Padding(inputSize, newW, newH)
+
+	// 尺寸一致时跳过缩放，保留原始图片类型以使用快速转换路径
+	resized := img
+	if newW != params.OrigW || newH != params.OrigH {
+		resized = imageutil.Resize(img, newW, newH)
+	}
+	ImageToCHW(dst, inputSize, inputSize, resized, params.PadX, params.PadY, NormUnit)
+
+	return params
+}
+
+// CenterCrop 将图片短边缩放到 inputSize 后居中裁剪，写入单张图片的输入数据 (CHW + Normalize 0-1)
+//
+// 与 Ultralytics 分类模型的预处理 (Resize + CenterCrop) 一致
+//
+// # Params:
+//
+//	dst: 单张图片的输入数据，长度为 3*inputSize*inputSize
+//	img: 原图
+//	inputSize: 模型输入尺寸
+func CenterCrop(dst []float32, img image.Image, inputSize int) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	scale := float64(inputSize) / float64(min(w, h))
+	newW := max(inputSize, int(math.Round(float64(w)*scale)))
+	newH := max(inputSize, int(math.Round(float64(h)*scale)))
+
+	resized := img
+	if newW != w || newH != h {
+		resized = imageutil.Resize(img, newW, newH)
+	}
+	origin := resized.Bounds().Min.Add(image.Pt((newW-inputSize)/2, (newH-inputSize)/2))
+	crop := cropImage(resized, image.Rectangle{Min: origin, Max: origin.Add(image.Pt(inputSize, inputSize))})
+	ImageToCHW(dst, inputSize, inputSize, crop, 0, 0, NormUnit)
+}
+
+// cropImage 裁剪图片，不支持 SubImage 的图片类型复制为 *image.RGBA
+func cropImage(img image.Image, r image.Rectangle) image.Image {
+	if s, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(r)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,98 @@
+package vision
+
+import (
+	"image"
+	"testing"
+)
+
+func TestLetterboxParams(t *testing.T) {
+	// 1280x960 的图片缩放到 640x480，居中放置时上下各填充 80
+	padX, padY := LetterboxCenter.Padding(640, 640, 480)
+	if padX != 0 || padY != 80 {
+		t.Fatalf("填充偏移应为 (0, 80)，实际 (%d, %d)", padX, padY)
+	}
+	if x, y := LetterboxTopLeft.Padding(640, 640, 480); x != 0 || y != 0 {
+		t.Fatalf("左上角对齐时不应有偏移，实际 (%d, %d)", x, y)
+	}
+
+	params := LetterboxParams{OrigW: 1280, OrigH: 960, Scale: 0.5, PadX: padX, PadY: padY}
+	if x, y := params.OrigX(320), params.OrigY(80); x != 640 || y != 0 {
+		t.Fatalf("映射回原图坐标应为 (640, 0)，实际 (%f, %f)", x, y)
+	}
+	if x, y := params.InputX(1280), params.InputY(960); x != 640 || y != 560 {
+		t.Fatalf("映射到输入坐标应为 (640, 560)，实际 (%f, %f)", x, y)
+	}
+}
+
+func TestLetterbox_Batch(t *testing.T) {
+	const inputSize = 8
+	planeSize := 3 * inputSize * inputSize
+	data := make([]float32, 2*planeSize)
+
+	// 不同尺寸的图片各自计算缩放与填充参数
+	wide := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	tall := image.NewRGBA(image.Rect(0, 0, 4, 8))
+	p0 := Letterbox(data[:planeSize], wide, inputSize, LetterboxCenter)
+	p1 := Letterbox(data[planeSize:], tall, inputSize, LetterboxCenter)
+
+	if p0.Scale != 0.5 || p0.PadX != 0 || p0.PadY != 2 {
+		t.Fatalf("宽图的参数错误: %+v", p0)
+	}
+	if p1.Scale != 1 || p1.PadX != 2 || p1.PadY != 0 {
+		t.Fatalf("高图的参数错误: %+v", p1)
+	}
+}
+
+func TestLetterboxParams_MaskLayout(t *testing.T) {
+	// 1280x960 缩放到 640x480，居中上下各填充 80，原型步长为 4
+	params := LetterboxParams{OrigW: 1280, OrigH: 960, Scale: 0.5, PadX: 0, PadY: 80}
+	box := image.Rect(100, 200, 301, 401)
+
+	size, maskBox, tr := params.MaskLayout(MaskOriginal, box, 4)
+	if size != image.Pt(1280, 960) || maskBox != box || tr.Scale != 0.125 || tr.OffsetY != 20 {
+		t.Fatalf("原图分辨率参数错误: %v %v %+v", size, maskBox, tr)
+	}
+
+	size, maskBox, tr = params.MaskLayout(MaskInput, box, 4)
+	if size != image.Pt(640, 480) || maskBox != image.Rect(50, 100, 151, 201) || tr.Scale != 0.25 || tr.OffsetY != 20 {
+		t.Fatalf("输入分辨率参数错误: %v %v %+v", size, maskBox, tr)
+	}
+
+	size, maskBox, tr = params.MaskLayout(MaskProto, box, 4)
+	if size != image.Pt(160, 120) || maskBox != image.Rect(12, 25, 38, 51) || tr.Scale != 1 || tr.OffsetY != 20 {
+		t.Fatalf("原型分辨率参数错误: %v %v %+v", size, maskBox, tr)
+	}
+}
+
+func TestCenterCrop(t *testing.T) {
+	const inputSize = 4
+	plane := inputSize * inputSize
+
+	// 短边与输入尺寸一致，左中右各 4 列分别为红、绿、蓝，裁剪后只剩中间的绿色
+	img := image.NewRGBA(image.Rect(0, 0, 12, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 12; x++ {
+			img.Pix[img.PixOffset(x, y)+x/4] = 255
+			img.Pix[img.PixOffset(x, y)+3] = 255
+		}
+	}
+	dst := make([]float32, 3*plane)
+	CenterCrop(dst, img, inputSize)
+	for i := 0; i < plane; i++ {
+		if dst[i] != 0 || dst[plane+i] != 1 || dst[2*plane+i] != 0 {
+			t.Fatalf("应裁剪出中间的绿色区域，实际 %v", dst)
+		}
+	}
+
+	// 需要缩放时裁剪后没有填充区域
+	white := image.NewGray(image.Rect(0, 0, 8, 16))
+	for i := range white.Pix {
+		white.Pix[i] = 255
+	}
+	CenterCrop(dst, white, inputSize)
+	for i, v := range dst {
+		if v != 1 {
+			t.Fatalf("第 %d 个数值应为 1，实际 %f", i, v)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package vision
+
+import (
+	"image"
+	"math"
+)
+
+// MaskResolution 分割 Mask 的输出分辨率
+type MaskResolution int
+
+const (
+	MaskOriginal MaskResolution = iota // 原图分辨率，Mask 与原图尺寸一致 (默认)
+	MaskInput                          // 模型输入分辨率，Mask 为原图等比缩放到 InputSize 后的尺寸 (不含填充区域)
+	MaskProto                          // 原型分辨率，Mask 为原图等比缩放到原型尺寸 (例如 160x160) 后的尺寸，内存占用最小
+)
+
+// MaskTransform Mask 坐标到原型坐标的映射：protoX = x*Scale + OffsetX，protoY = y*Scale + OffsetY
+type MaskTransform struct {
+	Scale            float32
+	OffsetX, OffsetY float32
+}
+
+// MaskProtos 分割模型输出的 Mask 原型
+type MaskProtos struct {
+	Data    []float32 // 单张图片的原型数据，形状为 [C, H, W]
+	C, H, W int
+}
+
+// Decode 解码单个实例的 Mask，结果写入 dst
+//
+// 先在原型分辨率上计算检测框覆盖区域的 Mask (系数与原型的线性组合)，再双线性插值到 dst 的分辨率，
+// 只有检测框内概率大于阈值的像素被置为 255
+//
+// # Params:
+//
+//	dst: 输出 Mask
+//	coeffs: 实例的 Mask 系数，长度为 C
+//	box: dst 坐标系下的检测框
+//	t: dst 坐标到原型坐标的映射
+//	threshold: 二值化阈值 (sigmoid 之后的概率)
+func (p MaskProtos) Decode(dst *image.Gray, coeffs []float32, box image.Rectangle, t MaskTransform, threshold float32) {
+	box = box.Intersect(dst.Rect)
+	if box.Empty() || p.W == 0 || p.H == 0 || threshold >= 1 {
+		return
+	}
+
+	// 检测框在原型上覆盖的区域 (像素中心对齐)，向外扩展 1 个像素用于插值
+	toProtoX := func(x float32) float32 { return (x+0.5)*t.Scale + t.OffsetX - 0.5 }
+	toProtoY := func(y float32) float32 { return (y+0.5)*t.Scale + t.OffsetY - 0.5 }
+	x0 := max(0, int(math.Floor(float64(toProtoX(float32(box.Min.X))))))
+	y0 := max(0, int(math.Floor(float64(toProtoY(float32(box.Min.Y))))))
+	x1 := min(p.W-1, int(math.Ceil(float64(toProtoX(float32(box.Max.X-1)))))+1)
+	y1 := min(p.H-1, int(math.Ceil(float64(toProtoY(float32(box.Max.Y-1)))))+1)
+	if x0 > x1 || y0 > y1 {
+		return
+	}
+
+	// 原型分辨率的 Mask (sigmoid 之前)，每个实例只计算一次
+	rw, rh := x1-x0+1, y1-y0+1
+	logits := make([]float32, rw*rh)
+	for k := 0; k < p.C; k++ {
+		coeff := coeffs[k]
+		plane := p.Data[k*p.H*p.W:]
+		for y := 0; y < rh; y++ {
+			src := plane[(y0+y)*p.W+x0:]
+			row := logits[y*rw : (y+1)*rw]
+			for x := range row {
+				row[x] += coeff * src[x]
+			}
+		}
+	}
+
+	// sigmoid(v) > threshold 等价于 v > logit(threshold)，避免逐像素计算 sigmoid
+	limit := float32(math.Inf(-1))
+	if threshold > 0 {
+		limit = float32(math.Log(float64(threshold) / float64(1-threshold)))
+	}
+
+	// 预先计算每列的插值位置
+	xIdx := make([]int, box.Dx())
+	xFrac := make([]float32, box.Dx())
+	for i := range xIdx {
+		u := min(max(toProtoX(float32(box.Min.X+i)), float32(x0)), float32(x1)) - float32(x0)
+		xIdx[i] = min(int(u), max(rw-2, 0))
+		xFrac[i] = u - float32(xIdx[i])
+	}
+
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		v := min(max(toProtoY(float32(y)), float32(y0)), float32(y1)) - float32(y0)
+		iy := min(int(v), max(rh-2, 0))
+		fy := v - float32(iy)
+		row0 := logits[iy*rw:]
+		row1 := row0
+		if rh > 1 {
+			row1 = logits[(iy+1)*rw:]
+		}
+
+		out := dst.Pix[dst.PixOffset(box.Min.X, y):]
+		for i := range xIdx {
+			ix, fx := xIdx[i], xFrac[i]
+			ix1 := min(ix+1, rw-1)
+			top := row0[ix] + (row0[ix1]-row0[ix])*fx
+			bottom := row1[ix] + (row1[ix1]-row1[ix])*fx
+			if top+(bottom-top)*fy > limit {
+				out[i] = 255
+			}
+		}
+	}
+}
+
+// PasteMask 将 Mask 按其 Bounds 绘制到画布上，与画布已有的像素取最大值
+//
+// # Params:
+//
+//	dst: 画布，例如整幅图片尺寸的 *image.Gray
+//	mask: 只保存了局部区域的 Mask，例如 SegResult.Mask
+func PasteMask(dst, mask *image.Gray) {
+	if mask == nil {
+		return
+	}
+	r := mask.Rect.Intersect(dst.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		src := mask.Pix[mask.PixOffset(r.Min.X, y):]
+		row := dst.Pix[dst.PixOffset(r.Min.X, y):]
+		for x := 0; x < r.Dx(); x++ {
+			row[x] = max(row[x], src[x])
+		}
+	}
+}
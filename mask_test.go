@@ -0,0 +1,122 @@
+package vision
+
+import (
+	"image"
+	"testing"
+)
+
+// stepProtos 单通道 4x4 原型，左两列为 +10，右两列为 -10
+func stepProtos() MaskProtos {
+	data := make([]float32, 16)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			data[y*4+x] = 10
+			if x >= 2 {
+				data[y*4+x] = -10
+			}
+		}
+	}
+	return MaskProtos{Data: data, C: 1, H: 4, W: 4}
+}
+
+func TestMaskProtos_Decode(t *testing.T) {
+	protos := stepProtos()
+	dst := image.NewGray(image.Rect(0, 0, 16, 16))
+
+	// 16x16 的 Mask 对应 4x4 的原型，步长为 4
+	protos.Decode(dst, []float32{1}, dst.Rect, MaskTransform{Scale: 0.25}, 0.5)
+
+	// 插值后的边界位于原型第 1、2 列中心之间，即 x = 7.5
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			want := uint8(0)
+			if x <= 7 {
+				want = 255
+			}
+			if got := dst.GrayAt(x, y).Y; got != want {
+				t.Fatalf("(%d, %d) 应为 %d，实际 %d", x, y, want, got)
+			}
+		}
+	}
+}
+
+func TestMaskProtos_DecodeBox(t *testing.T) {
+	protos := stepProtos()
+	dst := image.NewGray(image.Rect(0, 0, 16, 16))
+
+	// 只在检测框内二值化
+	box := image.Rect(4, 4, 12, 8)
+	protos.Decode(dst, []float32{1}, box, MaskTransform{Scale: 0.25}, 0.5)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			want := uint8(0)
+			if image.Pt(x, y).In(box) && x <= 7 {
+				want = 255
+			}
+			if got := dst.GrayAt(x, y).Y; got != want {
+				t.Fatalf("(%d, %d) 应为 %d，实际 %d", x, y, want, got)
+			}
+		}
+	}
+
+	// 系数取反后 Mask 位于右侧
+	dst = image.NewGray(image.Rect(0, 0, 16, 16))
+	protos.Decode(dst, []float32{-1}, dst.Rect, MaskTransform{Scale: 0.25}, 0.5)
+	if dst.GrayAt(0, 0).Y != 0 || dst.GrayAt(15, 15).Y != 255 {
+		t.Fatal("系数取反后 Mask 应位于右侧")
+	}
+
+	// 阈值为 1 时没有像素
+	dst = image.NewGray(image.Rect(0, 0, 16, 16))
+	protos.Decode(dst, []float32{1}, dst.Rect, MaskTransform{Scale: 0.25}, 1)
+	for _, v := range dst.Pix {
+		if v != 0 {
+			t.Fatal("阈值为 1 时不应有像素")
+		}
+	}
+}
+
+func TestMaskProtos_DecodeOffset(t *testing.T) {
+	protos := stepProtos()
+
+	// Mask 坐标平移 1 个原型像素后，边界左移 4 个像素
+	dst := image.NewGray(image.Rect(0, 0, 16, 16))
+	protos.Decode(dst, []float32{1}, dst.Rect, MaskTransform{Scale: 0.25, OffsetX: 1}, 0.5)
+	if dst.GrayAt(3, 0).Y != 255 || dst.GrayAt(4, 0).Y != 0 {
+		t.Fatalf("平移后边界应位于 x = 3.5")
+	}
+}
+
+func TestSegResult_FullMask(t *testing.T) {
+	mask := image.NewGray(image.Rect(10, 20, 14, 24))
+	for i := range mask.Pix {
+		mask.Pix[i] = 255
+	}
+	r := SegResult{Box: mask.Rect, Mask: mask, MaskSize: image.Pt(32, 32)}
+
+	// 可直接使用整幅图片的坐标访问
+	if r.Mask.GrayAt(10, 20).Y != 255 || r.Mask.GrayAt(0, 0).Y != 0 {
+		t.Fatal("Mask 应可使用整幅图片的坐标访问")
+	}
+
+	full := r.FullMask()
+	if full.Bounds() != image.Rect(0, 0, 32, 32) {
+		t.Fatalf("FullMask 尺寸应为 32x32，实际 %v", full.Bounds())
+	}
+	count := 0
+	for _, v := range full.Pix {
+		if v == 255 {
+			count++
+		}
+	}
+	if count != 16 || full.GrayAt(13, 23).Y != 255 || full.GrayAt(14, 23).Y != 0 {
+		t.Fatalf("FullMask 应只在检测框内有 16 个像素，实际 %d", count)
+	}
+
+	// 超出画布的部分被裁剪
+	canvas := image.NewGray(image.Rect(0, 0, 12, 22))
+	PasteMask(canvas, mask)
+	if canvas.GrayAt(11, 21).Y != 255 || canvas.GrayAt(9, 21).Y != 0 {
+		t.Fatal("PasteMask 应绘制到画布的对应位置")
+	}
+}
@@ -0,0 +1,589 @@
+package vision
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrModelMismatch 模型与引擎任务或配置不匹配
+var ErrModelMismatch = errors.New("模型与任务不匹配")
+
+// 模型任务类型，与 Ultralytics 元数据中的 task 一致
+const (
+	TaskDetect   = "detect"
+	TaskSegment  = "segment"
+	TaskClassify = "classify"
+	TaskPose     = "pose"
+	TaskOBB      = "obb"
+)
+
+// TensorInfo 模型输入/输出的名称和形状
+type TensorInfo struct {
+	Name  string
+	Shape []int64 // 动态维度为 -1
+}
+
+// Dim 第 i 个维度的大小，越界或动态维度返回 -1
+func (t TensorInfo) Dim(i int) int64 {
+	if i < 0 || i >= len(t.Shape) {
+		return -1
+	}
+	return t.Shape[i]
+}
+
+// ModelInfo ONNX 模型的输入输出及元数据
+type ModelInfo struct {
+	Inputs   []TensorInfo
+	Outputs  []TensorInfo
+	Metadata map[string]string // 自定义元数据，例如 Ultralytics 导出时写入的 task、names、kpt_shape、imgsz
+}
+
+// ReadModelInfo 读取 ONNX 模型的输入输出及元数据
+//
+// 按需读取文件中的 protobuf 字段，不会读取权重 (graph.initializer) 数据
+//
+// # Params:
+//
+//	modelPath: ONNX 模型路径
+func ReadModelInfo(modelPath string) (*ModelInfo, error) {
+	f, err := os.Open(modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取模型文件失败: %w", err)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("读取模型文件失败: %w", err)
+	}
+
+	info, err := parseModelInfo(f, stat.Size())
+	if err != nil {
+		return nil, fmt.Errorf("解析模型 %s 失败: %w", modelPath, err)
+	}
+	return info, nil
+}
+
+// ParseModelInfo 从 ONNX 模型数据 (ModelProto) 中解析输入输出及元数据
+func ParseModelInfo(data []byte) (*ModelInfo, error) {
+	return parseModelInfo(bytes.NewReader(data), int64(len(data)))
+}
+
+// parseModelInfo 从 r 的 [0, size) 中解析 ModelProto
+func parseModelInfo(r io.ReaderAt, size int64) (*ModelInfo, error) {
+	info := &ModelInfo{Metadata: map[string]string{}}
+
+	var graph *io.SectionReader
+	err := walkProtoAt(r, 0, size, func(field int, value *io.SectionReader, _ uint64) error {
+		switch field {
+		case 7: // graph
+			graph = value
+		case 14: // metadata_props
+			data, err := io.ReadAll(value)
+			if err != nil {
+				return err
+			}
+			var key, val string
+			if err := walkProto(data, func(f int, v []byte, _ uint64) error {
+				switch f {
+				case 1:
+					key = string(v)
+				case 2:
+					val = string(v)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			info.Metadata[key] = val
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if graph == nil {
+		return nil, fmt.Errorf("模型中不包含计算图")
+	}
+
+	// 旧版本的模型会把权重也列为输入，需要排除
+	initializers := map[string]bool{}
+	var inputs []TensorInfo
+	err = walkProtoAt(graph, 0, graph.Size(), func(field int, value *io.SectionReader, _ uint64) error {
+		switch field {
+		case 5: // initializer，只读取 name，跳过权重数据
+			return walkProtoAt(value, 0, value.Size(), func(f int, v *io.SectionReader, _ uint64) error {
+				if f != 8 {
+					return nil
+				}
+				name, err := io.ReadAll(v)
+				initializers[string(name)] = true
+				return err
+			})
+		case 11, 12: // input, output
+			data, err := io.ReadAll(value)
+			if err != nil {
+				return err
+			}
+			t, err := parseValueInfo(data)
+			if err != nil {
+				return err
+			}
+			if field == 11 {
+				inputs = append(inputs, t)
+			} else {
+				info.Outputs = append(info.Outputs, t)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range inputs {
+		if !initializers[t.Name] {
+			info.Inputs = append(info.Inputs, t)
+		}
+	}
+
+	return info, nil
+}
+
+// ModelIO 模型的输入输出名称
+type ModelIO struct {
+	Input  string // 图片输入，例如 images
+	Output string // 主输出，例如 output0
+	Protos string // 分割模型的 Mask 原型输出，例如 output1
+
+	BatchSize int // 输入固定的批大小，0 表示动态维度
+}
+
+// ResolveInput 校验模型任务及 [N, 3, H, W] 输入，补全输入尺寸
+//
+// 返回的 ModelIO 只包含 Input 和 BatchSize，输出由各引擎按任务解析
+//
+// # Params:
+//
+//	task: 引擎对应的任务类型，模型元数据中的 task 不一致时返回 ErrModelMismatch
+//	inputSize: 配置的输入尺寸，为 0 时依次使用模型的固定尺寸、训练时的尺寸 (imgsz)、默认尺寸 (分类 224，其他 640)
+func (m *ModelInfo) ResolveInput(task string, inputSize *int) (ModelIO, error) {
+	var mio ModelIO
+
+	if t := m.Task(); t != "" && t != task {
+		return mio, fmt.Errorf("%w: 模型任务为 %s，引擎任务为 %s", ErrModelMismatch, t, task)
+	}
+
+	// 输入 [N, 3, H, W]
+	if len(m.Inputs) != 1 {
+		return mio, fmt.Errorf("%w: 模型应有 1 个输入，实际 %d 个", ErrModelMismatch, len(m.Inputs))
+	}
+	input := m.Inputs[0]
+	if len(input.Shape) != 4 || (input.Dim(1) != 3 && input.Dim(1) != -1) {
+		return mio, fmt.Errorf("%w: 模型输入形状应为 [N, 3, H, W]，实际 %v", ErrModelMismatch, input.Shape)
+	}
+	mio.Input = input.Name
+	mio.BatchSize = max(int(input.Dim(0)), 0)
+
+	if h, w := input.Dim(2), input.Dim(3); h > 0 && w > 0 {
+		// 固定输入尺寸
+		if h != w {
+			return mio, fmt.Errorf("%w: 暂不支持非正方形输入 %dx%d", ErrModelMismatch, w, h)
+		}
+		if err := ResolveParam("InputSize", inputSize, int(h)); err != nil {
+			return mio, err
+		}
+	} else if h, w, ok := m.ImageSize(); ok && h == w && *inputSize == 0 {
+		// 动态输入尺寸，优先使用配置，其次使用训练时的尺寸
+		*inputSize = h
+	}
+	if *inputSize <= 0 {
+		*inputSize = 640
+		if task == TaskClassify {
+			*inputSize = 224
+		}
+	}
+	return mio, nil
+}
+
+// ClassNames 类别名称，优先使用配置的 names，其次使用模型元数据，都不存在时返回 nil
+func (m *ModelInfo) ClassNames(names []string) []string {
+	if len(names) > 0 {
+		return names
+	}
+	names, _ = m.Names()
+	return names
+}
+
+// ResolveNames 确定类别名称表，names 为空时使用任务对应的内置类别表
+// (分类 ImageNet，姿态 COCO person，旋转目标检测 DOTA，其他 COCO)
+//
+// # Params:
+//
+//	task: 任务类型
+//	numClasses: 类别数量，内置类别表的数量不一致时返回 nil；为 0 (端到端模型的输出中不包含类别数量) 时不校验
+//	names: 配置或模型元数据中的类别名称
+func ResolveNames(task string, numClasses int, names []string) []string {
+	if len(names) > 0 {
+		return names
+	}
+	var builtin []string
+	switch task {
+	case TaskClassify:
+		builtin = ImageNetClasses
+	case TaskPose:
+		builtin = COCOPoseClasses
+	case TaskOBB:
+		builtin = DOTAClasses
+	default:
+		builtin = COCOClasses
+	}
+	if numClasses != 0 && len(builtin) != numClasses {
+		return nil
+	}
+	return builtin
+}
+
+// SingleOutput 获取模型唯一的 ndim 维输出
+//
+// # Params:
+//
+//	taskName: 任务名称，用于错误信息，例如 "检测"
+//	ndim: 输出的维度数量
+func (m *ModelInfo) SingleOutput(taskName string, ndim int) (TensorInfo, error) {
+	if len(m.Outputs) != 1 {
+		return TensorInfo{}, fmt.Errorf("%w: %s模型应有 1 个输出，实际 %d 个", ErrModelMismatch, taskName, len(m.Outputs))
+	}
+	out := m.Outputs[0]
+	if len(out.Shape) != ndim {
+		return TensorInfo{}, fmt.Errorf("%w: %s模型输出应为 %d 维，实际 %v", ErrModelMismatch, taskName, ndim, out.Shape)
+	}
+	return out, nil
+}
+
+// ResolveParam 补全并校验单个模型参数
+//
+// # Params:
+//
+//	name: 参数名
+//	value: 配置中的值，为 0 时使用模型中的值
+//	fromModel: 从模型中得到的值，<= 0 表示未知，多个已知值之间必须一致
+func ResolveParam(name string, value *int, fromModel ...int) error {
+	known := 0
+	for _, v := range fromModel {
+		if v <= 0 {
+			continue
+		}
+		if known > 0 && v != known {
+			return fmt.Errorf("%w: 模型中的 %s 不一致 (%d 与 %d)", ErrModelMismatch, name, known, v)
+		}
+		known = v
+	}
+	if known == 0 {
+		return nil
+	}
+	if *value != 0 && *value != known {
+		return fmt.Errorf("%w: 配置的 %s 为 %d，模型为 %d", ErrModelMismatch, name, *value, known)
+	}
+	*value = known
+	return nil
+}
+
+// Task 模型任务类型，例如 detect、segment、pose、obb、classify，不存在时返回空字符串
+func (m *ModelInfo) Task() string {
+	return strings.TrimSpace(m.Metadata["task"])
+}
+
+// Names 类别名称表，解析自元数据中的 names，例如 {0: 'person', 1: 'bicycle'}
+func (m *ModelInfo) Names() ([]string, bool) {
+	raw, ok := m.Metadata["names"]
+	if !ok {
+		return nil, false
+	}
+	names, err := parsePyDict(raw)
+	if err != nil || len(names) == 0 {
+		return nil, false
+	}
+	return names, true
+}
+
+// KeyPointShape 关键点形状，解析自元数据中的 kpt_shape，例如 [17, 3]
+func (m *ModelInfo) KeyPointShape() (numKeyPoints, numDims int, ok bool) {
+	values, ok := parsePyIntList(m.Metadata["kpt_shape"])
+	if !ok || len(values) != 2 {
+		return 0, 0, false
+	}
+	return values[0], values[1], true
+}
+
+// ImageSize 模型训练时的输入尺寸，解析自元数据中的 imgsz，例如 [640, 640]
+func (m *ModelInfo) ImageSize() (h, w int, ok bool) {
+	values, ok := parsePyIntList(m.Metadata["imgsz"])
+	if !ok {
+		return 0, 0, false
+	}
+	switch len(values) {
+	case 1:
+		return values[0], values[0], true
+	case 2:
+		return values[0], values[1], true
+	}
+	return 0, 0, false
+}
+
+// parseValueInfo 解析 ValueInfoProto
+func parseValueInfo(data []byte) (TensorInfo, error) {
+	var t TensorInfo
+	err := walkProto(data, func(field int, value []byte, _ uint64) error {
+		switch field {
+		case 1: // name
+			t.Name = string(value)
+		case 2: // type -> tensor_type -> shape -> dim
+			return walkProto(value, func(f int, v []byte, _ uint64) error {
+				if f != 1 {
+					return nil
+				}
+				return walkProto(v, func(f int, v []byte, _ uint64) error {
+					if f != 2 {
+						return nil
+					}
+					t.Shape = []int64{}
+					return walkProto(v, func(f int, v []byte, _ uint64) error {
+						if f != 1 {
+							return nil
+						}
+						dim := int64(-1)
+						err := walkProto(v, func(f int, _ []byte, n uint64) error {
+							if f == 1 {
+								dim = int64(n)
+							}
+							return nil
+						})
+						t.Shape = append(t.Shape, dim)
+						return err
+					})
+				})
+			})
+		}
+		return nil
+	})
+	return t, err
+}
+
+// walkProto 遍历 protobuf 消息的字段
+//
+// length-delimited 字段通过 value 返回，varint 字段通过 n 返回
+func walkProto(data []byte, fn func(field int, value []byte, n uint64) error) error {
+	for len(data) > 0 {
+		key, k := readVarint(data)
+		if k <= 0 {
+			return fmt.Errorf("protobuf 数据格式错误")
+		}
+		data = data[k:]
+		field, wireType := int(key>>3), key&7
+
+		switch wireType {
+		case 0: // varint
+			n, k := readVarint(data)
+			if k <= 0 {
+				return fmt.Errorf("protobuf 数据格式错误")
+			}
+			data = data[k:]
+			if err := fn(field, nil, n); err != nil {
+				return err
+			}
+		case 1: // 64-bit
+			if len(data) < 8 {
+				return fmt.Errorf("protobuf 数据格式错误")
+			}
+			data = data[8:]
+		case 2: // length-delimited
+			l, k := readVarint(data)
+			if k <= 0 || uint64(len(data)-k) < l {
+				return fmt.Errorf("protobuf 数据格式错误")
+			}
+			value := data[k : k+int(l)]
+			data = data[k+int(l):]
+			if err := fn(field, value, 0); err != nil {
+				return err
+			}
+		case 5: // 32-bit
+			if len(data) < 4 {
+				return fmt.Errorf("protobuf 数据格式错误")
+			}
+			data = data[4:]
+		default:
+			return fmt.Errorf("不支持的 protobuf 字段类型: %d", wireType)
+		}
+	}
+	return nil
+}
+
+// walkProtoAt 遍历 r 中 [off, off+size) 范围内 protobuf 消息的字段
+//
+// 与 walkProto 相同，但 length-delimited 字段以 SectionReader 返回，由 fn 决定是否读取，
+// 未读取的字段 (例如权重数据) 直接跳过
+func walkProtoAt(r io.ReaderAt, off, size int64, fn func(field int, value *io.SectionReader, n uint64) error) error {
+	var buf [20]byte // key 与长度两个 varint
+	end := off + size
+	for off < end {
+		k, err := r.ReadAt(buf[:min(int64(len(buf)), end-off)], off)
+		if k == 0 {
+			if err == nil || err == io.EOF {
+				err = fmt.Errorf("protobuf 数据格式错误")
+			}
+			return err
+		}
+		data := buf[:k]
+
+		key, n := readVarint(data)
+		if n <= 0 {
+			return fmt.Errorf("protobuf 数据格式错误")
+		}
+		data = data[n:]
+		off += int64(n)
+		field, wireType := int(key>>3), key&7
+
+		switch wireType {
+		case 0: // varint
+			v, n := readVarint(data)
+			if n <= 0 {
+				return fmt.Errorf("protobuf 数据格式错误")
+			}
+			off += int64(n)
+			if err := fn(field, nil, v); err != nil {
+				return err
+			}
+		case 1: // 64-bit
+			off += 8
+		case 2: // length-delimited
+			l, n := readVarint(data)
+			if n <= 0 || l > uint64(end-off-int64(n)) {
+				return fmt.Errorf("protobuf 数据格式错误")
+			}
+			off += int64(n)
+			if err := fn(field, io.NewSectionReader(r, off, int64(l)), 0); err != nil {
+				return err
+			}
+			off += int64(l)
+		case 5: // 32-bit
+			off += 4
+		default:
+			return fmt.Errorf("不支持的 protobuf 字段类型: %d", wireType)
+		}
+	}
+	if off > end {
+		return fmt.Errorf("protobuf 数据格式错误")
+	}
+	return nil
+}
+
+// readVarint 读取 varint，返回值及占用的字节数，格式错误时字节数 <= 0
+func readVarint(data []byte) (uint64, int) {
+	var n uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		b := data[i]
+		n |= uint64(b&0x7f) << (7 * i)
+		if b < 0x80 {
+			return n, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// parsePyIntList 解析 Python 列表形式的整数，例如 [17, 3]
+func parsePyIntList(s string) ([]int, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || (s[0] != '[' && s[0] != '(') {
+		return nil, false
+	}
+	s = strings.TrimSpace(s[1 : len(s)-1])
+	if s == "" {
+		return nil, false
+	}
+	var values []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		values = append(values, v)
+	}
+	return values, true
+}
+
+// maxClassNames 类别名称表的最大长度，防止元数据中过大的类别 ID 导致过大的内存分配
+const maxClassNames = 100000
+
+// parsePyDict 解析 Python 字典形式的类别名称，例如 {0: 'person', 1: "potter's wheel"}
+//
+// 类别 ID 必须小于 maxClassNames
+func parsePyDict(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("names 格式错误")
+	}
+	body := s[1 : len(s)-1]
+
+	entries := map[int]string{}
+	maxKey := -1
+	for i := 0; ; {
+		for i < len(body) && (body[i] == ' ' || body[i] == ',' || body[i] == '\n') {
+			i++
+		}
+		if i >= len(body) {
+			break
+		}
+
+		// key
+		j := strings.IndexByte(body[i:], ':')
+		if j < 0 {
+			return nil, fmt.Errorf("names 格式错误")
+		}
+		key, err := strconv.Atoi(strings.TrimSpace(body[i : i+j]))
+		if err != nil || key < 0 {
+			return nil, fmt.Errorf("names 格式错误: %q", body[i:i+j])
+		}
+		if key >= maxClassNames {
+			return nil, fmt.Errorf("names 中的类别 ID %d 超过上限 %d", key, maxClassNames)
+		}
+		i += j + 1
+		for i < len(body) && body[i] == ' ' {
+			i++
+		}
+
+		// value
+		if i >= len(body) || (body[i] != '\'' && body[i] != '"') {
+			return nil, fmt.Errorf("names 格式错误")
+		}
+		quote := body[i]
+		i++
+		var sb strings.Builder
+		for ; i < len(body) && body[i] != quote; i++ {
+			if body[i] == '\\' && i+1 < len(body) {
+				i++
+			}
+			sb.WriteByte(body[i])
+		}
+		if i >= len(body) {
+			return nil, fmt.Errorf("names 格式错误")
+		}
+		i++
+
+		entries[key] = sb.String()
+		maxKey = max(maxKey, key)
+	}
+
+	names := make([]string, maxKey+1)
+	for k, v := range entries {
+		names[k] = v
+	}
+	return names, nil
+}
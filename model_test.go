@@ -0,0 +1,263 @@
+package vision
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// protoField 编码 length-delimited 字段
+func protoField(field int, value []byte) []byte {
+	b := protoVarint(uint64(field<<3 | 2))
+	b = append(b, protoVarint(uint64(len(value)))...)
+	return append(b, value...)
+}
+
+// protoVarintField 编码 varint 字段
+func protoVarintField(field int, v uint64) []byte {
+	return append(protoVarint(uint64(field<<3)), protoVarint(v)...)
+}
+
+func protoVarint(v uint64) []byte {
+	var b []byte
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func protoValueInfo(name string, dims ...any) []byte {
+	var shape []byte
+	for _, d := range dims {
+		switch d := d.(type) {
+		case int:
+			shape = append(shape, protoField(1, protoVarintField(1, uint64(d)))...)
+		case string:
+			shape = append(shape, protoField(1, protoField(2, []byte(d)))...)
+		}
+	}
+	tensorType := append(protoVarintField(1, 1), protoField(2, shape)...)
+	return append(protoField(1, []byte(name)), protoField(2, protoField(1, tensorType))...)
+}
+
+func protoMetadata(key, value string) []byte {
+	return protoField(14, append(protoField(1, []byte(key)), protoField(2, []byte(value))...))
+}
+
+func TestParseModelInfo(t *testing.T) {
+	var graph []byte
+	graph = append(graph, protoField(5, protoField(8, []byte("model.0.conv.weight")))...)
+	graph = append(graph, protoField(11, protoValueInfo("images", "batch", 3, 640, 640))...)
+	graph = append(graph, protoField(11, protoValueInfo("model.0.conv.weight", 16, 3, 3, 3))...)
+	graph = append(graph, protoField(12, protoValueInfo("output0", "batch", 56, 8400))...)
+
+	var model []byte
+	model = append(model, protoVarintField(1, 9)...)
+	model = append(model, protoField(7, graph)...)
+	model = append(model, protoMetadata("task", "pose")...)
+	model = append(model, protoMetadata("kpt_shape", "[17, 3]")...)
+	model = append(model, protoMetadata("imgsz", "[640, 640]")...)
+	model = append(model, protoMetadata("names", `{0: 'person', 1: "potter's wheel", 2: 'a\'b'}`)...)
+
+	info, err := ParseModelInfo(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantInputs := []TensorInfo{{Name: "images", Shape: []int64{-1, 3, 640, 640}}}
+	if !reflect.DeepEqual(info.Inputs, wantInputs) {
+		t.Fatalf("输入应为 %v，实际 %v", wantInputs, info.Inputs)
+	}
+	wantOutputs := []TensorInfo{{Name: "output0", Shape: []int64{-1, 56, 8400}}}
+	if !reflect.DeepEqual(info.Outputs, wantOutputs) {
+		t.Fatalf("输出应为 %v，实际 %v", wantOutputs, info.Outputs)
+	}
+	if info.Task() != "pose" {
+		t.Fatalf("task 应为 pose，实际 %s", info.Task())
+	}
+	if k, d, ok := info.KeyPointShape(); !ok || k != 17 || d != 3 {
+		t.Fatalf("kpt_shape 应为 [17, 3]，实际 [%d, %d]", k, d)
+	}
+	if h, w, ok := info.ImageSize(); !ok || h != 640 || w != 640 {
+		t.Fatalf("imgsz 应为 [640, 640]，实际 [%d, %d]", h, w)
+	}
+	names, ok := info.Names()
+	if want := []string{"person", "potter's wheel", "a'b"}; !ok || !reflect.DeepEqual(names, want) {
+		t.Fatalf("names 应为 %q，实际 %q", want, names)
+	}
+}
+
+func TestParseModelInfo_Invalid(t *testing.T) {
+	if _, err := ParseModelInfo([]byte{0x0a, 0xff}); err == nil {
+		t.Fatal("截断的数据应返回错误")
+	}
+	if _, err := ParseModelInfo(protoVarintField(1, 9)); err == nil {
+		t.Fatal("缺少计算图时应返回错误")
+	}
+	if _, err := ReadModelInfo("./not_exist.onnx"); err == nil || errors.Is(err, ErrModelMismatch) {
+		t.Fatalf("文件不存在时应返回读取错误，实际 %v", err)
+	}
+}
+
+// countingReaderAt 统计读取的字节数
+type countingReaderAt struct {
+	r io.ReaderAt
+	n int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestReadModelInfo_SkipsInitializers(t *testing.T) {
+	weights := make([]byte, 1<<20)
+	initializer := append(protoField(8, []byte("model.0.conv.weight")), protoField(9, weights)...)
+
+	var graph []byte
+	graph = append(graph, protoField(5, initializer)...)
+	graph = append(graph, protoField(11, protoValueInfo("images", 1, 3, 640, 640))...)
+	graph = append(graph, protoField(11, protoValueInfo("model.0.conv.weight", 16, 3, 3, 3))...)
+	graph = append(graph, protoField(12, protoValueInfo("output0", 1, 84, 8400))...)
+	model := append(protoField(7, graph), protoMetadata("task", "detect")...)
+
+	r := &countingReaderAt{r: bytes.NewReader(model)}
+	info, err := parseModelInfo(r, int64(len(model)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.n >= int64(len(weights)) {
+		t.Errorf("不应读取权重数据，实际读取 %d 字节", r.n)
+	}
+	if len(info.Inputs) != 1 || info.Inputs[0].Name != "images" || info.Task() != "detect" {
+		t.Errorf("解析结果错误: %+v", info)
+	}
+
+	path := filepath.Join(t.TempDir(), "model.onnx")
+	if err := os.WriteFile(path, model, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := ReadModelInfo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromFile, info) {
+		t.Errorf("从文件读取的结果应一致: %+v", fromFile)
+	}
+}
+
+func TestModelInfo_SingleOutput(t *testing.T) {
+	info := &ModelInfo{Outputs: []TensorInfo{{Name: "output0", Shape: []int64{1, 84, 8400}}}}
+	if out, err := info.SingleOutput("检测", 3); err != nil || out.Name != "output0" {
+		t.Fatalf("应返回 output0，实际 %v, %v", out, err)
+	}
+	if _, err := info.SingleOutput("检测", 4); !errors.Is(err, ErrModelMismatch) {
+		t.Errorf("维度不一致时应返回 ErrModelMismatch，实际 %v", err)
+	}
+	info.Outputs = append(info.Outputs, TensorInfo{Name: "output1"})
+	if _, err := info.SingleOutput("检测", 3); !errors.Is(err, ErrModelMismatch) {
+		t.Errorf("多个输出时应返回 ErrModelMismatch，实际 %v", err)
+	}
+}
+
+func TestResolveParam(t *testing.T) {
+	v := 0
+	if err := ResolveParam("NumClasses", &v, -1, 80, 80); err != nil || v != 80 {
+		t.Fatalf("应使用模型中的值 80，实际 %d, %v", v, err)
+	}
+	v = 0
+	if err := ResolveParam("NumClasses", &v, -1, 0); err != nil || v != 0 {
+		t.Fatalf("模型中的值未知时应保持 0，实际 %d, %v", v, err)
+	}
+	v = 10
+	if err := ResolveParam("NumClasses", &v, 80); !errors.Is(err, ErrModelMismatch) {
+		t.Errorf("配置与模型不一致时应返回 ErrModelMismatch，实际 %v", err)
+	}
+	v = 0
+	if err := ResolveParam("NumClasses", &v, 80, 10); !errors.Is(err, ErrModelMismatch) {
+		t.Errorf("模型中的值不一致时应返回 ErrModelMismatch，实际 %v", err)
+	}
+}
+
+func TestModelInfo_ResolveInput(t *testing.T) {
+	info := &ModelInfo{
+		Inputs:   []TensorInfo{{Name: "images", Shape: []int64{1, 3, 640, 640}}},
+		Metadata: map[string]string{"task": "detect"},
+	}
+	size := 0
+	mio, err := info.ResolveInput(TaskDetect, &size)
+	if err != nil || mio.Input != "images" || mio.BatchSize != 1 || size != 640 {
+		t.Fatalf("固定输入: %+v, %d, %v", mio, size, err)
+	}
+	size = 320
+	if _, err := info.ResolveInput(TaskDetect, &size); !errors.Is(err, ErrModelMismatch) {
+		t.Errorf("配置的输入尺寸与模型不一致时应返回 ErrModelMismatch，实际 %v", err)
+	}
+	size = 0
+	if _, err := info.ResolveInput(TaskPose, &size); !errors.Is(err, ErrModelMismatch) {
+		t.Errorf("任务不一致时应返回 ErrModelMismatch，实际 %v", err)
+	}
+
+	// 动态输入依次使用配置、imgsz、默认尺寸
+	info = &ModelInfo{Inputs: []TensorInfo{{Name: "images", Shape: []int64{-1, 3, -1, -1}}}, Metadata: map[string]string{}}
+	size = 0
+	if mio, err := info.ResolveInput(TaskClassify, &size); err != nil || mio.BatchSize != 0 || size != 224 {
+		t.Errorf("动态输入的分类模型默认尺寸应为 224: %+v, %d, %v", mio, size, err)
+	}
+	info.Metadata["imgsz"] = "[1024, 1024]"
+	size = 0
+	if _, err := info.ResolveInput(TaskDetect, &size); err != nil || size != 1024 {
+		t.Errorf("动态输入时应使用 imgsz: %d, %v", size, err)
+	}
+	size = 512
+	if _, err := info.ResolveInput(TaskDetect, &size); err != nil || size != 512 {
+		t.Errorf("动态输入时应优先使用配置: %d, %v", size, err)
+	}
+
+	info.Inputs[0].Shape = []int64{1, 1, 640, 640}
+	if _, err := info.ResolveInput(TaskDetect, &size); !errors.Is(err, ErrModelMismatch) {
+		t.Errorf("输入通道数不为 3 时应返回 ErrModelMismatch，实际 %v", err)
+	}
+}
+
+func TestResolveNames(t *testing.T) {
+	info := &ModelInfo{Metadata: map[string]string{"names": "{0: 'a', 1: 'b'}"}}
+	if names := info.ClassNames(nil); !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("应使用模型元数据中的类别名称，实际 %q", names)
+	}
+	if names := info.ClassNames([]string{"x"}); !reflect.DeepEqual(names, []string{"x"}) {
+		t.Errorf("应优先使用配置的类别名称，实际 %q", names)
+	}
+
+	if names := ResolveNames(TaskDetect, 80, nil); len(names) != 80 || names[0] != "person" {
+		t.Errorf("检测模型应使用 COCO 类别表，实际 %d 个", len(names))
+	}
+	if names := ResolveNames(TaskOBB, 0, nil); len(names) != len(DOTAClasses) {
+		t.Errorf("类别数量未知时应直接使用内置类别表，实际 %d 个", len(names))
+	}
+	if names := ResolveNames(TaskDetect, 3, nil); names != nil {
+		t.Errorf("类别数量与内置类别表不一致时应返回 nil，实际 %q", names)
+	}
+	if names := ResolveNames(TaskDetect, 3, []string{"a"}); !reflect.DeepEqual(names, []string{"a"}) {
+		t.Errorf("应优先使用指定的类别名称，实际 %q", names)
+	}
+}
+
+func TestParsePyDict_Limit(t *testing.T) {
+	if _, err := parsePyDict("{999999999: 'x'}"); err == nil {
+		t.Error("类别 ID 过大时应返回错误")
+	}
+	info := &ModelInfo{Metadata: map[string]string{"names": "{0: 'a', 999999999: 'x'}"}}
+	if names, ok := info.Names(); ok || names != nil {
+		t.Errorf("类别 ID 过大时不应返回类别名称，实际 %d 个", len(names))
+	}
+	if names, err := parsePyDict("{2: 'c', 0: 'a'}"); err != nil || !reflect.DeepEqual(names, []string{"a", "", "c"}) {
+		t.Errorf("不连续的类别 ID 应按 ID 放置，实际 %q, %v", names, err)
+	}
+}
@@ -0,0 +1,185 @@
+package vision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrPoolClosed 引擎池已关闭
+var ErrPoolClosed = errors.New("引擎池已关闭")
+
+// Pool 引擎池，持有同一模型的多个引擎实例，供并发调用方借用
+//
+// 单个引擎的 Predict 等方法不保证并发安全，通过 Pool 可以让每个引擎同一时刻只被一个调用方使用
+type Pool[T any] struct {
+	items   chan T
+	size    int // 已创建的引擎数量
+	destroy func(T)
+
+	mu        sync.Mutex
+	returned  *sync.Cond // 引擎归还或停止等待时通知 Close
+	borrowed  int        // 已借出的数量
+	waiting   int        // 正在 Acquire 中等待的数量
+	closed    bool
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPool 创建引擎池
+//
+// # Params:
+//
+//	size: 引擎数量
+//	newFn: 创建引擎，例如 func() (*yolov11.DetEngine, error) { return yolov11.NewDetEngine(cfg) }
+//	destroyFn: 释放引擎，例如 (*yolov11.DetEngine).Destroy
+func NewPool[T any](size int, newFn func() (T, error), destroyFn func(T)) (*Pool[T], error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("引擎池大小必须大于 0")
+	}
+
+	p := &Pool[T]{
+		items:   make(chan T, size),
+		destroy: destroyFn,
+		closeCh: make(chan struct{}),
+	}
+	p.returned = sync.NewCond(&p.mu)
+	for i := 0; i < size; i++ {
+		item, err := newFn()
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("创建第 %d 个引擎失败: %w", i+1, err)
+		}
+		p.items <- item
+		p.size++
+	}
+	return p, nil
+}
+
+// Size 引擎数量
+func (p *Pool[T]) Size() int {
+	return p.size
+}
+
+// Acquire 借出一个引擎，池中无空闲引擎时阻塞等待
+//
+// ctx 结束时返回 ctx.Err()，池关闭后返回 ErrPoolClosed，使用完毕后必须调用 Release 归还
+func (p *Pool[T]) Acquire(ctx context.Context) (T, error) {
+	var zero T
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return zero, ErrPoolClosed
+	}
+	p.waiting++
+	p.mu.Unlock()
+
+	select {
+	case item := <-p.items:
+		p.mu.Lock()
+		if !p.closed {
+			p.waiting--
+			p.borrowed++
+			p.mu.Unlock()
+			return item, nil
+		}
+		p.mu.Unlock()
+
+		// 在等待期间池被关闭，直接释放，释放完成后 Close 才会返回
+		if p.destroy != nil {
+			p.destroy(item)
+		}
+		p.stopWaiting()
+		return zero, ErrPoolClosed
+	case <-p.closeCh:
+		p.stopWaiting()
+		return zero, ErrPoolClosed
+	case <-ctx.Done():
+		p.stopWaiting()
+		return zero, ctx.Err()
+	}
+}
+
+// stopWaiting 停止等待借出
+func (p *Pool[T]) stopWaiting() {
+	p.mu.Lock()
+	p.waiting--
+	p.returned.Broadcast()
+	p.mu.Unlock()
+}
+
+// Release 归还引擎，池关闭后归还的引擎直接释放
+//
+// item 必须是 Acquire 借出的引擎，且每次借出只归还一次。引擎池只记录借出的数量，不检查引擎本身，
+// 归还次数多于借出次数时 panic，归还其他引擎不会被检测到
+func (p *Pool[T]) Release(item T) {
+	p.mu.Lock()
+	if p.borrowed == 0 {
+		p.mu.Unlock()
+		panic("vision: Pool.Release 归还了未借出的引擎")
+	}
+
+	if p.closed {
+		p.mu.Unlock()
+		if p.destroy != nil {
+			p.destroy(item)
+		}
+		// 释放完成后再通知，保证 Close 返回时所有引擎都已释放
+		p.mu.Lock()
+		p.borrowed--
+		p.returned.Broadcast()
+		p.mu.Unlock()
+		return
+	}
+	p.borrowed--
+
+	// 持有锁发送，保证 Close 清空空闲引擎后不会再有引擎放回
+	select {
+	case p.items <- item:
+		p.mu.Unlock()
+	default:
+		p.mu.Unlock()
+		panic("vision: Pool.Release 归还的引擎超过引擎池大小")
+	}
+}
+
+// Do 借出一个引擎执行 fn，执行结束后自动归还
+func (p *Pool[T]) Do(ctx context.Context, fn func(T) error) error {
+	item, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.Release(item)
+	return fn(item)
+}
+
+// Close 关闭引擎池
+//
+// 关闭后不再借出引擎，空闲的引擎立即释放，已借出的引擎在 Release 时释放，所有引擎释放后返回
+func (p *Pool[T]) Close() {
+	p.closeOnce.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+		close(p.closeCh)
+
+		for drained := false; !drained; {
+			select {
+			case item := <-p.items:
+				if p.destroy != nil {
+					p.destroy(item)
+				}
+			default:
+				drained = true
+			}
+		}
+
+		// 等待中的 Acquire 可能已取出空闲引擎，由其直接释放
+		p.mu.Lock()
+		for p.borrowed > 0 || p.waiting > 0 {
+			p.returned.Wait()
+		}
+		p.mu.Unlock()
+	})
+}
@@ -0,0 +1,206 @@
+package vision
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeEngine struct {
+	id        int
+	destroyed atomic.Bool
+}
+
+func newFakePool(t *testing.T, size int) (*Pool[*fakeEngine], []*fakeEngine) {
+	var engines []*fakeEngine
+	p, err := NewPool(size, func() (*fakeEngine, error) {
+		e := &fakeEngine{id: len(engines)}
+		engines = append(engines, e)
+		return e, nil
+	}, func(e *fakeEngine) {
+		e.destroyed.Store(true)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, engines
+}
+
+func TestPool_ConcurrentDo(t *testing.T) {
+	p, _ := newFakePool(t, 3)
+	defer p.Close()
+
+	var inUse, maxInUse atomic.Int32
+	busy := make(map[*fakeEngine]bool)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := p.Do(context.Background(), func(e *fakeEngine) error {
+				mu.Lock()
+				if busy[e] {
+					t.Error("同一个引擎被同时借出")
+				}
+				busy[e] = true
+				mu.Unlock()
+
+				n := inUse.Add(1)
+				for {
+					m := maxInUse.Load()
+					if n <= m || maxInUse.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				inUse.Add(-1)
+
+				mu.Lock()
+				busy[e] = false
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInUse.Load() > 3 {
+		t.Fatalf("同时借出的引擎数不应超过 3，实际 %d", maxInUse.Load())
+	}
+}
+
+func TestPool_AcquireCanceled(t *testing.T) {
+	p, _ := newFakePool(t, 1)
+	defer p.Close()
+
+	e, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Release(e)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("应返回 context.DeadlineExceeded，实际 %v", err)
+	}
+}
+
+func TestPool_CloseDrainsInFlight(t *testing.T) {
+	p, engines := newFakePool(t, 2)
+
+	e, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("存在借出的引擎时 Close 不应返回")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if e.destroyed.Load() {
+		t.Fatal("借出中的引擎不应被释放")
+	}
+	if _, err := p.Acquire(context.Background()); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("关闭后应返回 ErrPoolClosed，实际 %v", err)
+	}
+
+	p.Release(e)
+	<-closed
+	for _, e := range engines {
+		if !e.destroyed.Load() {
+			t.Fatalf("引擎 %d 未被释放", e.id)
+		}
+	}
+}
+
+func TestPool_NewFailure(t *testing.T) {
+	created, destroyed := 0, 0
+	_, err := NewPool(3, func() (*fakeEngine, error) {
+		if created == 2 {
+			return nil, errors.New("模型加载失败")
+		}
+		created++
+		return &fakeEngine{}, nil
+	}, func(e *fakeEngine) {
+		destroyed++
+	})
+	if err == nil {
+		t.Fatal("创建失败时应返回错误")
+	}
+	if destroyed != 2 {
+		t.Fatalf("已创建的 2 个引擎应被释放，实际 %d 个", destroyed)
+	}
+}
+
+func TestPool_ReleaseAfterClose(t *testing.T) {
+	p, engines := newFakePool(t, 2)
+
+	e, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+
+	// 空闲的引擎立即释放
+	idle := engines[0]
+	if idle == e {
+		idle = engines[1]
+	}
+	deadline := time.Now().Add(time.Second)
+	for !idle.destroyed.Load() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !idle.destroyed.Load() || e.destroyed.Load() {
+		t.Fatal("关闭后应立即释放空闲引擎，借出中的引擎在归还时释放")
+	}
+
+	// 关闭后归还的引擎直接释放，不会阻塞
+	p.Release(e)
+	if !e.destroyed.Load() {
+		t.Fatal("关闭后归还的引擎应被释放")
+	}
+	<-closed
+}
+
+func TestPool_ReleaseMisuse(t *testing.T) {
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s 应 panic", name)
+			}
+		}()
+		fn()
+	}
+
+	p, _ := newFakePool(t, 1)
+	e, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Release(e)
+	mustPanic("重复归还", func() { p.Release(e) })
+	mustPanic("没有借出的引擎时归还其他引擎", func() { p.Release(&fakeEngine{}) })
+
+	p.Close()
+	mustPanic("关闭后归还未借出的引擎", func() { p.Release(e) })
+}
@@ -0,0 +1,92 @@
+package vision
+
+import "image"
+
+// DetResult 目标检测结果
+type DetResult struct {
+	// 分类ID，例如：
+	//	0: person
+	//  1: bicycle
+	//  2: car
+	// - 详细映射参考：
+	//	https://github.com/ultralytics/ultralytics/blob/main/ultralytics/cfg/datasets/coco.yaml
+	ClassID   int
+	ClassName string // 类别名称，例如 person
+	Score     float32
+	Box       image.Rectangle // 检测框
+}
+
+// SegResult 分割结果
+type SegResult struct {
+	// 分类ID，例如：
+	//	0: person
+	//  1: bicycle
+	//  2: car
+	// 详细映射参考：
+	//	https://github.com/ultralytics/ultralytics/blob/main/ultralytics/cfg/datasets/coco.yaml
+	ClassID   int
+	ClassName string // 类别名称，例如 person
+	Score     float32
+	Box       image.Rectangle // 分割出的矩形区域
+	// 解码后的 Mask，只保存检测框区域，Bounds 即为 Mask 在整幅图片中的位置，
+	// 可直接使用整幅图片的坐标调用 At、GrayAt，框外的像素为 0
+	Mask     *image.Gray
+	MaskSize image.Point // Mask 坐标系下整幅图片的尺寸，MaskResolution 为 MaskOriginal 时与原图尺寸一致
+}
+
+// FullMask 整幅图片尺寸的 Mask，与 Mask 只保存检测框区域之前的格式一致
+func (r SegResult) FullMask() *image.Gray {
+	full := image.NewGray(image.Rect(0, 0, r.MaskSize.X, r.MaskSize.Y))
+	PasteMask(full, r.Mask)
+	return full
+}
+
+// Polygons 提取 Mask 的轮廓，坐标为 Mask 坐标系下整幅图片的坐标
+func (r SegResult) Polygons(opts ContourOptions) []Polygon {
+	return MaskPolygons(r.Mask, opts)
+}
+
+// RLE 将 Mask 编码为 COCO 格式的 RLE，尺寸为 MaskSize
+func (r SegResult) RLE() RLE {
+	return EncodeRLE(r.Mask, r.MaskSize.X, r.MaskSize.Y)
+}
+
+// ClassResult 分类结果
+type ClassResult struct {
+	// 分类ID，例如：
+	//	436: station wagon
+	//	656: minivan
+	// 详细映射参考：
+	//	https://github.com/ultralytics/ultralytics/blob/main/ultralytics/cfg/datasets/ImageNet.yaml
+	ClassID   int
+	ClassName string // 类别名称，例如 station wagon
+	Score     float32
+}
+
+// KeyPoint 单个关键点
+type KeyPoint struct {
+	X, Y  int     // 原图坐标
+	Score float32 // 可见性/置信度
+}
+
+// PoseResult 姿态估计结果
+type PoseResult struct {
+	//	https://github.com/ultralytics/ultralytics/blob/main/ultralytics/cfg/datasets/coco-pose.yaml
+	ClassID   int
+	ClassName string // 类别名称，例如 person
+	Score     float32
+	Box       image.Rectangle
+	KeyPoints []KeyPoint // 关键点列表
+}
+
+// OBBResult 旋转目标检测结果
+type OBBResult struct {
+	ClassID   int
+	ClassName string // 类别名称，例如 plane
+	Score     float32
+	// 旋转框的顶点坐标：TopLeft, TopRight, BottomRight, BottomLeft
+	Corners [4]image.Point
+
+	Center image.Point
+	Angle  float32 // 弧度
+}
@@ -0,0 +1,237 @@
+package vision
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// RLE COCO 格式的游程编码 Mask，与 pycocotools 的编码结果一致
+//
+// 像素按列优先 (Fortran) 顺序展开，Counts 从背景开始交替记录背景、前景的游程长度
+type RLE struct {
+	Height, Width int
+	Counts        []int // 未压缩的游程长度
+}
+
+// EncodeRLE 将 Mask 编码为 RLE，像素值 > 127 视为前景
+//
+// # Params:
+//
+//	mask: 二值 Mask，Bounds 可以只覆盖图片的一部分 (例如 SegResult.Mask)，区域外按背景处理
+//	width, height: 整幅图片的尺寸
+func EncodeRLE(mask *image.Gray, width, height int) RLE {
+	rle := RLE{Height: height, Width: width}
+	var r image.Rectangle
+	if mask != nil {
+		r = mask.Rect.Intersect(image.Rect(0, 0, width, height))
+	}
+
+	run, fg := 0, false
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			v := image.Pt(x, y).In(r) && mask.Pix[mask.PixOffset(x, y)] > 127
+			if v != fg {
+				rle.Counts = append(rle.Counts, run)
+				run, fg = 0, v
+			}
+			run++
+		}
+	}
+	rle.Counts = append(rle.Counts, run)
+	return rle
+}
+
+// Decode 解码为整幅图片尺寸的 Mask，前景为 255
+func (r RLE) Decode() *image.Gray {
+	mask := image.NewGray(image.Rect(0, 0, r.Width, r.Height))
+	if r.Height == 0 {
+		return mask
+	}
+	pos := 0
+	for i, n := range r.Counts {
+		if i%2 == 1 {
+			for p := pos; p < pos+n && p < r.Width*r.Height; p++ {
+				mask.Pix[(p%r.Height)*mask.Stride+p/r.Height] = 255
+			}
+		}
+		pos += n
+	}
+	return mask
+}
+
+// Area 前景像素数
+func (r RLE) Area() int {
+	area := 0
+	for i := 1; i < len(r.Counts); i += 2 {
+		area += r.Counts[i]
+	}
+	return area
+}
+
+// BBox 前景的外接矩形，与 pycocotools 的 toBbox 一致 (x, y, w, h 分别为 Min.X, Min.Y, Dx, Dy)，无前景时返回空矩形
+func (r RLE) BBox() image.Rectangle {
+	m := len(r.Counts) / 2 * 2
+	if m == 0 || r.Height == 0 {
+		return image.Rectangle{}
+	}
+	xs, ys, xe, ye := r.Width, r.Height, 0, 0
+	cc, xp := 0, 0
+	for j := 0; j < m; j++ {
+		cc += r.Counts[j]
+		t := cc - j%2
+		y, x := t%r.Height, t/r.Height
+		if j%2 == 0 {
+			xp = x
+		} else if xp < x {
+			// 前景跨越多列，覆盖整列的高度
+			ys, ye = 0, r.Height-1
+		}
+		xs, xe = min(xs, x), max(xe, x)
+		ys, ye = min(ys, y), max(ye, y)
+	}
+	return image.Rect(xs, ys, xe+1, ye+1)
+}
+
+// String 压缩后的 counts 字符串，与 pycocotools 的 encode 结果一致
+//
+// 从第 3 个游程开始记录与前两个游程的差值，每 5 位一组编码为可打印字符
+func (r RLE) String() string {
+	var sb strings.Builder
+	for i, x := range r.Counts {
+		if i > 2 {
+			x -= r.Counts[i-2]
+		}
+		for more := true; more; {
+			c := x & 0x1f
+			x >>= 5
+			if c&0x10 != 0 {
+				more = x != -1
+			} else {
+				more = x != 0
+			}
+			if more {
+				c |= 0x20
+			}
+			sb.WriteByte(byte(c + 48))
+		}
+	}
+	return sb.String()
+}
+
+// ParseRLE 解析压缩后的 counts 字符串
+//
+// # Params:
+//
+//	height, width: 图片尺寸，即 COCO 中的 size
+//	counts: 压缩后的 counts 字符串
+func ParseRLE(height, width int, counts string) (RLE, error) {
+	rle := RLE{Height: height, Width: width}
+	for p := 0; p < len(counts); {
+		x, k := 0, 0
+		for more := true; more; {
+			if p >= len(counts) {
+				return RLE{}, fmt.Errorf("RLE 字符串不完整")
+			}
+			c := int(counts[p]) - 48
+			if c < 0 || c > 0x3f {
+				return RLE{}, fmt.Errorf("RLE 字符串包含非法字符: %q", counts[p])
+			}
+			x |= (c & 0x1f) << (5 * k)
+			more = c&0x20 != 0
+			p++
+			k++
+			if !more && c&0x10 != 0 {
+				x |= -1 << (5 * k)
+			}
+		}
+		if m := len(rle.Counts); m > 2 {
+			x += rle.Counts[m-2]
+		}
+		if x < 0 {
+			return RLE{}, fmt.Errorf("RLE 游程长度为负数")
+		}
+		rle.Counts = append(rle.Counts, x)
+	}
+	return rle, nil
+}
+
+// rleJSON COCO 标注中 segmentation 的 RLE 格式
+type rleJSON struct {
+	Size   [2]int          `json:"size"` // [height, width]
+	Counts json.RawMessage `json:"counts"`
+}
+
+// MarshalJSON 编码为 {"size": [h, w], "counts": "..."}，counts 为压缩后的字符串
+func (r RLE) MarshalJSON() ([]byte, error) {
+	counts, err := json.Marshal(r.String())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(rleJSON{Size: [2]int{r.Height, r.Width}, Counts: counts})
+}
+
+// UnmarshalJSON 解析 COCO 格式的 RLE，counts 可以是压缩后的字符串或未压缩的整数列表
+func (r *RLE) UnmarshalJSON(data []byte) error {
+	var v rleJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	var s string
+	if err := json.Unmarshal(v.Counts, &s); err == nil {
+		rle, err := ParseRLE(v.Size[0], v.Size[1], s)
+		if err != nil {
+			return err
+		}
+		*r = rle
+		return nil
+	}
+	*r = RLE{Height: v.Size[0], Width: v.Size[1]}
+	return json.Unmarshal(v.Counts, &r.Counts)
+}
+
+// RLEIoU 计算两个 RLE 的 IoU，与 pycocotools 的 iou 一致
+//
+// # Params:
+//
+//	dt, gt: 预测与真值的 RLE，尺寸应相同
+//	crowd: gt 是否为 crowd 标注，为 true 时以 dt 的面积作为分母
+func RLEIoU(dt, gt RLE, crowd bool) float64 {
+	inter := rleIntersection(dt, gt)
+	union := dt.Area()
+	if !crowd {
+		union += gt.Area() - inter
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(inter) / float64(union)
+}
+
+// rleIntersection 同时遍历两个 RLE 的游程，计算前景的交集面积
+func rleIntersection(a, b RLE) int {
+	inter := 0
+	i, j := 0, 0
+	ra, rb := 0, 0 // 当前游程剩余长度
+	for {
+		for ra == 0 && i < len(a.Counts) {
+			ra = a.Counts[i]
+			i++
+		}
+		for rb == 0 && j < len(b.Counts) {
+			rb = b.Counts[j]
+			j++
+		}
+		if ra == 0 || rb == 0 {
+			return inter
+		}
+		n := min(ra, rb)
+		// 第 i 个游程 (从 1 开始) 为偶数时是前景
+		if i%2 == 0 && j%2 == 0 {
+			inter += n
+		}
+		ra -= n
+		rb -= n
+	}
+}
@@ -0,0 +1,132 @@
+package vision
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeRLE(t *testing.T) {
+	// 2 行 3 列，按列展开为 0 0 1 1 0 1
+	m := image.NewGray(image.Rect(0, 0, 3, 2))
+	m.SetGray(1, 0, color.Gray{Y: 255})
+	m.SetGray(1, 1, color.Gray{Y: 255})
+	m.SetGray(2, 1, color.Gray{Y: 255})
+
+	rle := EncodeRLE(m, 3, 2)
+	if !reflect.DeepEqual(rle.Counts, []int{2, 2, 1, 1}) {
+		t.Fatalf("Counts %v，预期 [2 2 1 1]", rle.Counts)
+	}
+	if s := rle.String(); s != "221O" {
+		t.Errorf("压缩字符串 %q，预期 %q", s, "221O")
+	}
+	if rle.Area() != 3 {
+		t.Errorf("面积应为 3，实际 %d", rle.Area())
+	}
+	if bbox := rle.BBox(); bbox != image.Rect(1, 0, 3, 2) {
+		t.Errorf("外接矩形 %v，预期 (1,0)-(3,2)", bbox)
+	}
+	if got := rle.Decode(); !reflect.DeepEqual(got.Pix, m.Pix) {
+		t.Errorf("解码结果 %v，预期 %v", got.Pix, m.Pix)
+	}
+
+	// 全背景、全前景
+	if rle := EncodeRLE(image.NewGray(image.Rect(0, 0, 4, 4)), 4, 4); !reflect.DeepEqual(rle.Counts, []int{16}) {
+		t.Errorf("全背景 Counts %v，预期 [16]", rle.Counts)
+	}
+	full := image.NewGray(image.Rect(0, 0, 4, 4))
+	fillMask(full, full.Rect)
+	if rle := EncodeRLE(full, 4, 4); !reflect.DeepEqual(rle.Counts, []int{0, 16}) {
+		t.Errorf("全前景 Counts %v，预期 [0 16]", rle.Counts)
+	}
+}
+
+func TestRLE_String(t *testing.T) {
+	cases := []struct {
+		counts []int
+		want   string
+	}{
+		{[]int{0, 4, 1}, "041"},
+		{[]int{100, 3}, "T33"},
+		// 与前两个游程的差值为负数
+		{[]int{5, 3, 2, 1}, "532N"},
+		{[]int{0, 100, 0, 1}, "0T30mL"},
+	}
+	for _, c := range cases {
+		rle := RLE{Height: 10, Width: 100, Counts: c.counts}
+		if s := rle.String(); s != c.want {
+			t.Errorf("%v 压缩为 %q，预期 %q", c.counts, s, c.want)
+		}
+		parsed, err := ParseRLE(10, 100, c.want)
+		if err != nil {
+			t.Fatalf("解析 %q 失败: %v", c.want, err)
+		}
+		if !reflect.DeepEqual(parsed.Counts, c.counts) {
+			t.Errorf("%q 解析为 %v，预期 %v", c.want, parsed.Counts, c.counts)
+		}
+	}
+
+	if _, err := ParseRLE(10, 10, "T"); err == nil {
+		t.Error("不完整的字符串应返回错误")
+	}
+}
+
+func TestRLE_JSON(t *testing.T) {
+	rle := RLE{Height: 2, Width: 3, Counts: []int{2, 2, 1, 1}}
+	data, err := json.Marshal(rle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"size":[2,3],"counts":"221O"}` {
+		t.Errorf("JSON %s", data)
+	}
+
+	for _, s := range []string{string(data), `{"size":[2,3],"counts":[2,2,1,1]}`} {
+		var got RLE
+		if err := json.Unmarshal([]byte(s), &got); err != nil {
+			t.Fatalf("解析 %s 失败: %v", s, err)
+		}
+		if !reflect.DeepEqual(got, rle) {
+			t.Errorf("%s 解析为 %+v，预期 %+v", s, got, rle)
+		}
+	}
+}
+
+func TestRLEIoU(t *testing.T) {
+	a := image.NewGray(image.Rect(0, 0, 10, 10))
+	fillMask(a, image.Rect(0, 0, 4, 4))
+	b := image.NewGray(image.Rect(0, 0, 10, 10))
+	fillMask(b, image.Rect(2, 0, 6, 4))
+	ra, rb := EncodeRLE(a, 10, 10), EncodeRLE(b, 10, 10)
+
+	// 交集 8，并集 24
+	if iou := RLEIoU(ra, rb, false); iou != 8.0/24 {
+		t.Errorf("IoU 应为 %v，实际 %v", 8.0/24, iou)
+	}
+	// crowd 时以 dt 的面积为分母
+	if iou := RLEIoU(ra, rb, true); iou != 0.5 {
+		t.Errorf("crowd IoU 应为 0.5，实际 %v", iou)
+	}
+	empty := EncodeRLE(nil, 10, 10)
+	if iou := RLEIoU(empty, empty, false); iou != 0 {
+		t.Errorf("空 Mask 的 IoU 应为 0，实际 %v", iou)
+	}
+}
+
+func TestSegResult_RLE(t *testing.T) {
+	// 只保存检测框区域的 Mask 与整幅图片的 Mask 编码结果一致
+	mask := image.NewGray(image.Rect(3, 2, 7, 6))
+	fillMask(mask, image.Rect(4, 3, 6, 5))
+	r := SegResult{Mask: mask, MaskSize: image.Pt(10, 8)}
+
+	got := r.RLE()
+	want := EncodeRLE(r.FullMask(), 10, 8)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RLE %+v，预期 %+v", got, want)
+	}
+	if !reflect.DeepEqual(got.Decode().Pix, r.FullMask().Pix) {
+		t.Error("解码结果与整幅图片的 Mask 不一致")
+	}
+}
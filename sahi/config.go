@@ -0,0 +1,61 @@
+package sahi
+
+import "fmt"
+
+// MergeMode 相邻切片重复结果的合并方式
+type MergeMode int
+
+const (
+	MergeGreedy MergeMode = iota // 贪心合并 (Greedy NMM)，重复的结果合并为一个，检测框与 Mask 取并集
+	MergeNMS                     // 非极大值抑制，只保留得分最高的结果
+)
+
+// MatchMetric 判断两个结果是否重复的度量
+type MatchMetric int
+
+const (
+	MatchIOS MatchMetric = iota // 交集与较小面积之比，适合被切片边缘截断的目标
+	MatchIOU                    // 交并比
+)
+
+// Config 切片推理的参数
+type Config struct {
+	SliceWidth   int     // 切片宽度 (默认 640)
+	SliceHeight  int     // 切片高度 (默认 640)
+	OverlapRatio float64 // 相邻切片的重叠比例，取值 [0, 1) (默认 0.2)
+	FullImage    bool    // 是否额外对整张图片推理，用于检出跨越多个切片的大目标 (默认 true)
+	BatchSize    int     // 每次送入引擎的切片数量，模型支持动态批大小时一次推理 (默认 4)
+
+	Merge          MergeMode   // 重复结果的合并方式 (默认 MergeGreedy)
+	MatchMetric    MatchMetric // 重复判断的度量 (默认 MatchIOS)
+	MatchThreshold float32     // 重复判断的阈值，度量值不小于该值时视为重复 (默认 0.5)
+	AgnosticMerge  bool        // 是否跨类别合并 (默认 false，只合并同类别的结果)
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() Config {
+	return Config{
+		SliceWidth:     640,
+		SliceHeight:    640,
+		OverlapRatio:   0.2,
+		FullImage:      true,
+		BatchSize:      4,
+		Merge:          MergeGreedy,
+		MatchMetric:    MatchIOS,
+		MatchThreshold: 0.5,
+	}
+}
+
+// validate 校验配置
+func (c Config) validate() error {
+	if c.SliceWidth <= 0 || c.SliceHeight <= 0 {
+		return fmt.Errorf("切片尺寸无效: %dx%d", c.SliceWidth, c.SliceHeight)
+	}
+	if c.OverlapRatio < 0 || c.OverlapRatio >= 1 {
+		return fmt.Errorf("切片重叠比例应在 [0, 1) 之间，实际 %v", c.OverlapRatio)
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("BatchSize 应大于 0，实际 %d", c.BatchSize)
+	}
+	return nil
+}
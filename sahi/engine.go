@@ -0,0 +1,241 @@
+package sahi
+
+import (
+	"context"
+	"fmt"
+	"github.com/getcharzp/go-vision"
+	"image"
+)
+
+// Detector 切片推理的目标检测器，包装 yolov11.DetEngine、yolo26.DetEngine 等 vision.Detector
+type Detector struct {
+	engine vision.Detector
+	config Config
+}
+
+// NewDetector 初始化切片推理的目标检测器
+//
+// # Params:
+//
+//	engine: 目标检测引擎，Destroy 时一并释放
+//	cfg: 切片参数
+func NewDetector(engine vision.Detector, cfg Config) (*Detector, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &Detector{engine: engine, config: cfg}, nil
+}
+
+// Destroy 释放引擎
+func (d *Detector) Destroy() {
+	d.engine.Destroy()
+}
+
+// Names 被包装引擎的类别名称表，引擎未实现 vision.Namer 时为 nil
+func (d *Detector) Names() []string {
+	return engineNames(d.engine)
+}
+
+// Predict 切片执行检测推理，结果为原图坐标
+func (d *Detector) Predict(img image.Image) ([]vision.DetResult, error) {
+	return d.PredictContext(context.Background(), img)
+}
+
+// PredictContext 切片执行检测推理，ctx 传递给引擎
+func (d *Detector) PredictContext(ctx context.Context, img image.Image) ([]vision.DetResult, error) {
+	results, err := predictSlices(img, d.config, func(imgs []image.Image) ([][]vision.DetResult, error) {
+		return d.engine.PredictBatchContext(ctx, imgs)
+	}, func(r vision.DetResult, offset image.Point) vision.DetResult {
+		r.Box = r.Box.Add(offset)
+		return r
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeResults(results, d.config, detOps), nil
+}
+
+// PredictBatch 逐张图片切片执行检测推理
+func (d *Detector) PredictBatch(imgs []image.Image) ([][]vision.DetResult, error) {
+	return d.PredictBatchContext(context.Background(), imgs)
+}
+
+// PredictBatchContext 逐张图片切片执行检测推理，ctx 传递给引擎
+func (d *Detector) PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]vision.DetResult, error) {
+	return predictEach(imgs, func(img image.Image) ([]vision.DetResult, error) {
+		return d.PredictContext(ctx, img)
+	})
+}
+
+// Segmenter 切片推理的实例分割器，包装 yolov11.SegEngine、yolo26.SegEngine 等 vision.Segmenter
+//
+// 结果中的 Mask 为原图坐标，合并后的 Mask 覆盖各切片结果的并集区域，
+// 引擎的 MaskResolution 需为 vision.MaskOriginal
+type Segmenter struct {
+	engine vision.Segmenter
+	config Config
+}
+
+// NewSegmenter 初始化切片推理的实例分割器
+//
+// # Params:
+//
+//	engine: 实例分割引擎，Destroy 时一并释放；实现 vision.MaskResolver 时 MaskResolution 必须为 vision.MaskOriginal
+//	cfg: 切片参数
+func NewSegmenter(engine vision.Segmenter, cfg Config) (*Segmenter, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if r, ok := engine.(vision.MaskResolver); ok && r.MaskResolution() != vision.MaskOriginal {
+		return nil, fmt.Errorf("切片推理要求引擎的 MaskResolution 为 MaskOriginal，实际为 %d", r.MaskResolution())
+	}
+	return &Segmenter{engine: engine, config: cfg}, nil
+}
+
+// Destroy 释放引擎
+func (s *Segmenter) Destroy() {
+	s.engine.Destroy()
+}
+
+// Names 被包装引擎的类别名称表，引擎未实现 vision.Namer 时为 nil
+func (s *Segmenter) Names() []string {
+	return engineNames(s.engine)
+}
+
+// MaskResolution 结果中 Mask 的分辨率，始终为 vision.MaskOriginal
+func (s *Segmenter) MaskResolution() vision.MaskResolution {
+	return vision.MaskOriginal
+}
+
+// Predict 切片执行分割推理，结果为原图坐标
+func (s *Segmenter) Predict(img image.Image) ([]vision.SegResult, error) {
+	return s.PredictContext(context.Background(), img)
+}
+
+// PredictContext 切片执行分割推理，ctx 传递给引擎
+func (s *Segmenter) PredictContext(ctx context.Context, img image.Image) ([]vision.SegResult, error) {
+	results, err := predictSlices(img, s.config, func(imgs []image.Image) ([][]vision.SegResult, error) {
+		return s.engine.PredictBatchContext(ctx, imgs)
+	}, func(r vision.SegResult, offset image.Point) vision.SegResult {
+		r.Box = r.Box.Add(offset)
+		r.Mask = shiftMask(r.Mask, offset)
+		return r
+	})
+	if err != nil {
+		return nil, err
+	}
+	results = mergeResults(results, s.config, segOps)
+	for i := range results {
+		results[i].MaskSize = img.Bounds().Size()
+	}
+	return results, nil
+}
+
+// PredictBatch 逐张图片切片执行分割推理
+func (s *Segmenter) PredictBatch(imgs []image.Image) ([][]vision.SegResult, error) {
+	return s.PredictBatchContext(context.Background(), imgs)
+}
+
+// PredictBatchContext 逐张图片切片执行分割推理，ctx 传递给引擎
+func (s *Segmenter) PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]vision.SegResult, error) {
+	return predictEach(imgs, func(img image.Image) ([]vision.SegResult, error) {
+		return s.PredictContext(ctx, img)
+	})
+}
+
+// OBBDetector 切片推理的旋转目标检测器，包装 yolov11.OBBEngine、yolo26.OBBEngine 等 vision.OBBDetector
+type OBBDetector struct {
+	engine vision.OBBDetector
+	config Config
+}
+
+// NewOBBDetector 初始化切片推理的旋转目标检测器
+//
+// # Params:
+//
+//	engine: 旋转目标检测引擎，Destroy 时一并释放
+//	cfg: 切片参数
+func NewOBBDetector(engine vision.OBBDetector, cfg Config) (*OBBDetector, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &OBBDetector{engine: engine, config: cfg}, nil
+}
+
+// Destroy 释放引擎
+func (d *OBBDetector) Destroy() {
+	d.engine.Destroy()
+}
+
+// Names 被包装引擎的类别名称表，引擎未实现 vision.Namer 时为 nil
+func (d *OBBDetector) Names() []string {
+	return engineNames(d.engine)
+}
+
+// Predict 切片执行旋转目标检测，结果为原图坐标
+func (d *OBBDetector) Predict(img image.Image) ([]vision.OBBResult, error) {
+	return d.PredictContext(context.Background(), img)
+}
+
+// PredictContext 切片执行旋转目标检测，ctx 传递给引擎
+func (d *OBBDetector) PredictContext(ctx context.Context, img image.Image) ([]vision.OBBResult, error) {
+	results, err := predictSlices(img, d.config, func(imgs []image.Image) ([][]vision.OBBResult, error) {
+		return d.engine.PredictBatchContext(ctx, imgs)
+	}, func(r vision.OBBResult, offset image.Point) vision.OBBResult {
+		for i := range r.Corners {
+			r.Corners[i] = r.Corners[i].Add(offset)
+		}
+		r.Center = r.Center.Add(offset)
+		return r
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeResults(results, d.config, obbOps), nil
+}
+
+// PredictBatch 逐张图片切片执行旋转目标检测
+func (d *OBBDetector) PredictBatch(imgs []image.Image) ([][]vision.OBBResult, error) {
+	return d.PredictBatchContext(context.Background(), imgs)
+}
+
+// PredictBatchContext 逐张图片切片执行旋转目标检测，ctx 传递给引擎
+func (d *OBBDetector) PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]vision.OBBResult, error) {
+	return predictEach(imgs, func(img image.Image) ([]vision.OBBResult, error) {
+		return d.PredictContext(ctx, img)
+	})
+}
+
+// predictEach 逐张图片推理
+func predictEach[T any](imgs []image.Image, predict func(img image.Image) ([]T, error)) ([][]T, error) {
+	results := make([][]T, len(imgs))
+	for i, img := range imgs {
+		res, err := predict(img)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = res
+	}
+	return results, nil
+}
+
+// engineNames 获取引擎的类别名称表
+func engineNames(engine any) []string {
+	if n, ok := engine.(vision.Namer); ok {
+		return n.Names()
+	}
+	return nil
+}
+
+// 切片推理器实现的通用接口
+var (
+	_ vision.Detector    = (*Detector)(nil)
+	_ vision.Segmenter   = (*Segmenter)(nil)
+	_ vision.OBBDetector = (*OBBDetector)(nil)
+
+	_ vision.Namer = (*Detector)(nil)
+	_ vision.Namer = (*Segmenter)(nil)
+	_ vision.Namer = (*OBBDetector)(nil)
+
+	_ vision.MaskResolver = (*Segmenter)(nil)
+)
@@ -0,0 +1,170 @@
+package sahi
+
+import (
+	"github.com/getcharzp/go-vision"
+	"image"
+	"sort"
+)
+
+// mergeOps 合并不同类型结果所需的操作
+type mergeOps[T any] struct {
+	score   func(r *T) float32
+	classID func(r *T) int
+	// overlap 两个结果的交集面积及各自面积
+	overlap func(a, b *T) (inter, areaA, areaB float64)
+	// merge 将 src 合并到 dst，仅 MergeGreedy 使用
+	merge func(dst, src *T)
+}
+
+// mergeResults 合并相邻切片之间的重复结果
+//
+// 按得分从高到低遍历，与当前结果重复的低分结果被抑制 (MergeNMS) 或合并到当前结果中 (MergeGreedy)
+func mergeResults[T any](results []T, cfg Config, ops mergeOps[T]) []T {
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return ops.score(&results[order[i]]) > ops.score(&results[order[j]])
+	})
+
+	removed := make([]bool, len(results))
+	merged := make([]T, 0, len(results))
+	for oi, i := range order {
+		if removed[i] {
+			continue
+		}
+		kept := results[i]
+		for _, j := range order[oi+1:] {
+			if removed[j] {
+				continue
+			}
+			if !cfg.AgnosticMerge && ops.classID(&results[i]) != ops.classID(&results[j]) {
+				continue
+			}
+			inter, areaA, areaB := ops.overlap(&results[i], &results[j])
+			if !matched(inter, areaA, areaB, cfg) {
+				continue
+			}
+			removed[j] = true
+			if cfg.Merge == MergeGreedy {
+				ops.merge(&kept, &results[j])
+			}
+		}
+		merged = append(merged, kept)
+	}
+	return merged
+}
+
+// matched 根据交集面积判断两个结果是否重复
+func matched(inter, areaA, areaB float64, cfg Config) bool {
+	if inter <= 0 {
+		return false
+	}
+	var v float64
+	switch cfg.MatchMetric {
+	case MatchIOU:
+		v = inter / (areaA + areaB - inter)
+	default:
+		v = inter / min(areaA, areaB)
+	}
+	return v >= float64(cfg.MatchThreshold)
+}
+
+// rectOverlap 两个矩形的交集面积及各自面积
+func rectOverlap(a, b image.Rectangle) (inter, areaA, areaB float64) {
+	areaA = float64(a.Dx() * a.Dy())
+	areaB = float64(b.Dx() * b.Dy())
+	r := a.Intersect(b)
+	return float64(r.Dx() * r.Dy()), areaA, areaB
+}
+
+// polygonOverlap 两个凸多边形的交集面积及各自面积
+func polygonOverlap(a, b []image.Point) (inter, areaA, areaB float64) {
+	areaA, areaB = polygonArea(a), polygonArea(b)
+	iou := float64(vision.PolygonIOU(a, b))
+	if iou <= 0 {
+		return 0, areaA, areaB
+	}
+	// iou = inter / (A + B - inter)
+	return iou * (areaA + areaB) / (1 + iou), areaA, areaB
+}
+
+// polygonArea 多边形面积 (鞋带公式)
+func polygonArea(pts []image.Point) float64 {
+	var sum float64
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		sum += float64(pts[i].X*pts[j].Y - pts[j].X*pts[i].Y)
+	}
+	if sum < 0 {
+		sum = -sum
+	}
+	return sum / 2
+}
+
+// unionMask 合并两个 Mask，返回覆盖两者区域的新 Mask
+func unionMask(a, b *image.Gray) *image.Gray {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	dst := image.NewGray(a.Rect.Union(b.Rect))
+	for _, m := range []*image.Gray{a, b} {
+		for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+			src := m.Pix[m.PixOffset(m.Rect.Min.X, y):]
+			row := dst.Pix[dst.PixOffset(m.Rect.Min.X, y):]
+			for x := 0; x < m.Rect.Dx(); x++ {
+				row[x] = max(row[x], src[x])
+			}
+		}
+	}
+	return dst
+}
+
+// shiftMask 平移 Mask，与原 Mask 共享像素数据
+func shiftMask(m *image.Gray, offset image.Point) *image.Gray {
+	if m == nil {
+		return nil
+	}
+	return &image.Gray{Pix: m.Pix, Stride: m.Stride, Rect: m.Rect.Add(offset)}
+}
+
+// detOps 目标检测结果的合并操作
+var detOps = mergeOps[vision.DetResult]{
+	score:   func(r *vision.DetResult) float32 { return r.Score },
+	classID: func(r *vision.DetResult) int { return r.ClassID },
+	overlap: func(a, b *vision.DetResult) (float64, float64, float64) {
+		return rectOverlap(a.Box, b.Box)
+	},
+	merge: func(dst, src *vision.DetResult) {
+		dst.Box = dst.Box.Union(src.Box)
+	},
+}
+
+// segOps 分割结果的合并操作，按检测框判断是否重复
+var segOps = mergeOps[vision.SegResult]{
+	score:   func(r *vision.SegResult) float32 { return r.Score },
+	classID: func(r *vision.SegResult) int { return r.ClassID },
+	overlap: func(a, b *vision.SegResult) (float64, float64, float64) {
+		return rectOverlap(a.Box, b.Box)
+	},
+	merge: func(dst, src *vision.SegResult) {
+		dst.Box = dst.Box.Union(src.Box)
+		dst.Mask = unionMask(dst.Mask, src.Mask)
+	},
+}
+
+// obbOps 旋转目标检测结果的合并操作
+//
+// 旋转框的并集不是旋转框，MergeGreedy 时保留得分最高的旋转框
+var obbOps = mergeOps[vision.OBBResult]{
+	score:   func(r *vision.OBBResult) float32 { return r.Score },
+	classID: func(r *vision.OBBResult) int { return r.ClassID },
+	overlap: func(a, b *vision.OBBResult) (float64, float64, float64) {
+		return polygonOverlap(a.Corners[:], b.Corners[:])
+	},
+	merge: func(dst, src *vision.OBBResult) {},
+}
@@ -0,0 +1,233 @@
+package sahi
+
+import (
+	"context"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// fakeDetector 将图片中白色像素的外接矩形作为检测结果
+type fakeDetector struct {
+	batches []int
+}
+
+func (f *fakeDetector) Predict(img image.Image) ([]vision.DetResult, error) {
+	return f.PredictContext(context.Background(), img)
+}
+
+func (f *fakeDetector) PredictContext(_ context.Context, img image.Image) ([]vision.DetResult, error) {
+	var box image.Rectangle
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r > 0 {
+				box = box.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if box.Empty() {
+		return nil, nil
+	}
+	return []vision.DetResult{{ClassID: 0, Score: 0.9, Box: box}}, nil
+}
+
+func (f *fakeDetector) PredictBatch(imgs []image.Image) ([][]vision.DetResult, error) {
+	return f.PredictBatchContext(context.Background(), imgs)
+}
+
+func (f *fakeDetector) PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]vision.DetResult, error) {
+	f.batches = append(f.batches, len(imgs))
+	results := make([][]vision.DetResult, len(imgs))
+	for i, img := range imgs {
+		results[i], _ = f.PredictContext(ctx, img)
+	}
+	return results, nil
+}
+
+func (f *fakeDetector) Destroy() {}
+
+func TestSlices(t *testing.T) {
+	cfg := DefaultConfig()
+
+	// 宽 1000 时步长为 512，最后一列与右边缘对齐
+	rects := Slices(image.Rect(0, 0, 1000, 600), cfg)
+	want := []image.Rectangle{image.Rect(0, 0, 640, 600), image.Rect(360, 0, 1000, 600)}
+	if len(rects) != len(want) {
+		t.Fatalf("切片应为 %v，实际 %v", want, rects)
+	}
+	for i := range want {
+		if rects[i] != want[i] {
+			t.Fatalf("切片应为 %v，实际 %v", want, rects)
+		}
+	}
+
+	// 切片覆盖整张图片
+	bounds := image.Rect(100, 50, 3100, 2050)
+	var covered image.Rectangle
+	for _, r := range Slices(bounds, cfg) {
+		if !r.In(bounds) {
+			t.Fatalf("切片 %v 超出图片区域 %v", r, bounds)
+		}
+		covered = covered.Union(r)
+	}
+	if covered != bounds {
+		t.Fatalf("切片应覆盖整张图片，实际 %v", covered)
+	}
+
+	if rects := Slices(image.Rect(0, 0, 320, 240), cfg); len(rects) != 1 || rects[0] != image.Rect(0, 0, 320, 240) {
+		t.Fatalf("小图应只有一个切片，实际 %v", rects)
+	}
+}
+
+func TestDetector_MergeAcrossSlices(t *testing.T) {
+	// 目标横跨两个切片
+	img := image.NewRGBA(image.Rect(0, 0, 1000, 600))
+	draw.Draw(img, image.Rect(300, 200, 700, 300), image.NewUniform(color.White), image.Point{}, draw.Src)
+	obj := image.Rect(300, 200, 700, 300)
+
+	for _, mode := range []MergeMode{MergeGreedy, MergeNMS} {
+		engine := &fakeDetector{}
+		cfg := DefaultConfig()
+		cfg.Merge = mode
+		cfg.BatchSize = 2
+		d, err := NewDetector(engine, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results, err := d.Predict(img)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || !results[0].Box.In(obj) {
+			t.Fatalf("合并方式 %d: 应只保留 1 个结果，实际 %v", mode, results)
+		}
+		if mode == MergeGreedy && results[0].Box != obj {
+			t.Fatalf("贪心合并后应为完整的目标 %v，实际 %v", obj, results[0].Box)
+		}
+		// 2 个切片 + 整图，每批 2 张
+		if len(engine.batches) != 2 || engine.batches[0] != 2 || engine.batches[1] != 1 {
+			t.Fatalf("批次应为 [2 1]，实际 %v", engine.batches)
+		}
+	}
+}
+
+func TestDetector_GreedyUnion(t *testing.T) {
+	// 不做整图推理时，两个切片各检出目标的一部分，贪心合并后为完整的目标
+	img := image.NewRGBA(image.Rect(0, 0, 1000, 600))
+	draw.Draw(img, image.Rect(300, 200, 700, 300), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	cfg := DefaultConfig()
+	cfg.FullImage = false
+	d, _ := NewDetector(&fakeDetector{}, cfg)
+	results, err := d.Predict(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Box != image.Rect(300, 200, 700, 300) {
+		t.Fatalf("应合并为完整的目标，实际 %v", results)
+	}
+}
+
+func TestMergeResults_ClassAware(t *testing.T) {
+	results := []vision.DetResult{
+		{ClassID: 0, Score: 0.9, Box: image.Rect(0, 0, 100, 100)},
+		{ClassID: 1, Score: 0.8, Box: image.Rect(0, 0, 100, 100)},
+		{ClassID: 0, Score: 0.7, Box: image.Rect(10, 10, 90, 90)},
+	}
+	cfg := DefaultConfig()
+	if merged := mergeResults(results, cfg, detOps); len(merged) != 2 {
+		t.Fatalf("按类别合并应保留 2 个结果，实际 %v", merged)
+	}
+	cfg.AgnosticMerge = true
+	if merged := mergeResults(results, cfg, detOps); len(merged) != 1 || merged[0].ClassID != 0 {
+		t.Fatalf("跨类别合并应保留得分最高的 1 个结果，实际 %v", merged)
+	}
+}
+
+func TestMergeResults_OBB(t *testing.T) {
+	square := func(x, y, size int) [4]image.Point {
+		return [4]image.Point{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}
+	}
+	results := []vision.OBBResult{
+		{ClassID: 0, Score: 0.6, Corners: square(5, 0, 100)},
+		{ClassID: 0, Score: 0.9, Corners: square(0, 0, 100)},
+		{ClassID: 0, Score: 0.8, Corners: square(500, 500, 100)},
+	}
+	merged := mergeResults(results, DefaultConfig(), obbOps)
+	if len(merged) != 2 || merged[0].Score != 0.9 {
+		t.Fatalf("应保留 2 个结果且第一个为最高分，实际 %v", merged)
+	}
+}
+
+func TestMergeResults_SegMask(t *testing.T) {
+	left := image.NewGray(image.Rect(0, 0, 10, 10))
+	left.SetGray(9, 5, color.Gray{Y: 255})
+	right := shiftMask(image.NewGray(image.Rect(0, 0, 10, 10)), image.Pt(8, 0))
+	right.SetGray(10, 5, color.Gray{Y: 255})
+
+	results := []vision.SegResult{
+		{Score: 0.9, Box: image.Rect(5, 4, 10, 6), Mask: left},
+		{Score: 0.8, Box: image.Rect(8, 4, 12, 6), Mask: right},
+	}
+	merged := mergeResults(results, DefaultConfig(), segOps)
+	if len(merged) != 1 {
+		t.Fatalf("应合并为 1 个结果，实际 %d 个", len(merged))
+	}
+	m := merged[0].Mask
+	if m.Bounds() != image.Rect(0, 0, 18, 10) || m.GrayAt(9, 5).Y != 255 || m.GrayAt(10, 5).Y != 255 {
+		t.Fatalf("Mask 应为两者的并集，实际 %v", m.Bounds())
+	}
+	if merged[0].Box != image.Rect(5, 4, 12, 6) {
+		t.Fatalf("检测框应为两者的并集，实际 %v", merged[0].Box)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.OverlapRatio = 1
+	if _, err := NewDetector(&fakeDetector{}, cfg); err == nil {
+		t.Fatal("重叠比例为 1 时应返回错误")
+	}
+}
+
+// fakeSegmenter 不输出结果，只用于检查 Mask 分辨率
+type fakeSegmenter struct {
+	res vision.MaskResolution
+}
+
+func (f *fakeSegmenter) Predict(image.Image) ([]vision.SegResult, error) {
+	return nil, nil
+}
+
+func (f *fakeSegmenter) PredictContext(context.Context, image.Image) ([]vision.SegResult, error) {
+	return nil, nil
+}
+
+func (f *fakeSegmenter) PredictBatch(imgs []image.Image) ([][]vision.SegResult, error) {
+	return make([][]vision.SegResult, len(imgs)), nil
+}
+
+func (f *fakeSegmenter) PredictBatchContext(_ context.Context, imgs []image.Image) ([][]vision.SegResult, error) {
+	return make([][]vision.SegResult, len(imgs)), nil
+}
+
+func (f *fakeSegmenter) Destroy() {}
+
+func (f *fakeSegmenter) MaskResolution() vision.MaskResolution {
+	return f.res
+}
+
+func TestNewSegmenter_MaskResolution(t *testing.T) {
+	if _, err := NewSegmenter(&fakeSegmenter{res: vision.MaskOriginal}, DefaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range []vision.MaskResolution{vision.MaskInput, vision.MaskProto} {
+		if _, err := NewSegmenter(&fakeSegmenter{res: res}, DefaultConfig()); err == nil {
+			t.Errorf("MaskResolution 为 %d 时应返回错误", res)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package sahi
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Slices 计算图片的切片区域
+//
+// 切片从左上角开始按 (1-OverlapRatio) 的步长排列，最后一行 (列) 与图片边缘对齐，
+// 图片小于切片尺寸时只返回整张图片
+//
+// # Params:
+//
+//	bounds: 图片区域
+//	cfg: 切片参数，使用其中的 SliceWidth、SliceHeight、OverlapRatio
+func Slices(bounds image.Rectangle, cfg Config) []image.Rectangle {
+	xs := sliceStarts(bounds.Dx(), cfg.SliceWidth, cfg.OverlapRatio)
+	ys := sliceStarts(bounds.Dy(), cfg.SliceHeight, cfg.OverlapRatio)
+
+	rects := make([]image.Rectangle, 0, len(xs)*len(ys))
+	for _, y := range ys {
+		for _, x := range xs {
+			r := image.Rect(x, y, x+cfg.SliceWidth, y+cfg.SliceHeight).Add(bounds.Min)
+			rects = append(rects, r.Intersect(bounds))
+		}
+	}
+	return rects
+}
+
+// sliceStarts 计算单个方向上各切片的起点
+func sliceStarts(size, slice int, overlap float64) []int {
+	if size <= slice {
+		return []int{0}
+	}
+	step := max(1, int(float64(slice)*(1-overlap)))
+
+	var starts []int
+	for start := 0; ; start += step {
+		if start+slice >= size {
+			starts = append(starts, size-slice)
+			break
+		}
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+// crop 裁剪切片，返回原点为 (0, 0) 的图片
+func crop(img image.Image, r image.Rectangle) image.Image {
+	if r == img.Bounds() && r.Min == (image.Point{}) {
+		return img
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	return dst
+}
+
+// predictSlices 对所有切片 (及整张图片) 推理，结果映射回原图坐标
+//
+// # Params:
+//
+//	img: 原图
+//	cfg: 切片参数
+//	predict: 引擎的批量推理
+//	shift: 将切片上的结果平移 offset 映射到原图
+func predictSlices[T any](img image.Image, cfg Config, predict func(imgs []image.Image) ([][]T, error), shift func(r T, offset image.Point) T) ([]T, error) {
+	bounds := img.Bounds()
+	rects := Slices(bounds, cfg)
+	if cfg.FullImage && len(rects) > 1 {
+		rects = append(rects, bounds)
+	}
+
+	var results []T
+	for start := 0; start < len(rects); start += cfg.BatchSize {
+		batch := rects[start:min(start+cfg.BatchSize, len(rects))]
+		imgs := make([]image.Image, len(batch))
+		for i, r := range batch {
+			imgs[i] = crop(img, r)
+		}
+
+		batchResults, err := predict(imgs)
+		if err != nil {
+			return nil, err
+		}
+		for i, res := range batchResults {
+			for _, r := range res {
+				results = append(results, shift(r, batch[i].Min))
+			}
+		}
+	}
+	return results, nil
+}
@@ -7,8 +7,8 @@ type Label int
 const (
 	LabelBackground  Label = 0 // 背景/排除
 	LabelForeground  Label = 1 // 前景/点击
-	LabelBoxTopLeft  Label = 2 // 框选左上
-	LabelBoxBotRight Label = 3 // 框选右下
+	LabelBoxTopLeft  Label = 2 // 框选左上，推荐使用 Prompt.Boxes
+	LabelBoxBotRight Label = 3 // 框选右下，推荐使用 Prompt.Boxes
 )
 
 // 均值和方差常量
@@ -22,11 +22,19 @@ const (
 	StdR = 0.229
 )
 
+// imageNorm 图片归一化参数
+var imageNorm = vision.TensorNorm{
+	Mean: [3]float32{MeanR, MeanG, MeanB},
+	Std:  [3]float32{StdR, StdG, StdB},
+}
+
 const (
 	// inputSize 输入图片的长边尺寸
 	inputSize = 1024
 	// maskThreshold 阈值
 	maskThreshold = 0.0
+	// lowResSize decoder 输出的低分辨率 Mask 边长
+	lowResSize = 256
 )
 
 type Point struct {
@@ -38,7 +46,7 @@ type Point struct {
 type Config struct {
 	// 必填参数
 	OnnxRuntimeLibPath string // onnxruntime.dll (或 .so, .dylib) 的路径
-	EncodeModelPath    string // 图片特征提取模型
+	EncodeModelPath    string // 图片特征提取模型，只通过 Engine.LoadContext 加载图片特征时可以为空
 	DecodeModelPath    string // Mask解码模型
 
 	// 可选参数
@@ -1,12 +1,15 @@
 package sam2
 
 import (
+	"context"
 	"fmt"
 	"github.com/getcharzp/go-vision"
 	ort "github.com/getcharzp/onnxruntime_purego"
 	"github.com/up-zero/gotool/convertutil"
 	"github.com/up-zero/gotool/imageutil"
 	"image"
+	"math"
+	"slices"
 )
 
 // Engine 持有 ONNX Session，负责创建 ImageContext
@@ -14,6 +17,7 @@ type Engine struct {
 	encoderSession *ort.Session
 	decoderSession *ort.Session
 	config         Config
+	hasMaskInput   bool // decoder 是否有 mask_input/has_mask_input 输入
 }
 
 // NewEngine 初始化 sam2 引擎
@@ -27,16 +31,22 @@ func NewEngine(cfg Config) (*Engine, error) {
 		return nil, err
 	}
 
-	// encoder session
-	encSession, err := oc.OnnxEngine.NewSession(cfg.EncodeModelPath, oc.SessionOptions)
-	if err != nil {
-		return nil, fmt.Errorf("创建 Encoder ONNX 会话失败: %w", err)
+	// encoder session，只通过 LoadContext 加载图片特征时可以不加载
+	var encSession *ort.Session
+	if cfg.EncodeModelPath != "" {
+		var err error
+		encSession, err = oc.OnnxEngine.NewSession(cfg.EncodeModelPath, oc.SessionOptions)
+		if err != nil {
+			return nil, fmt.Errorf("创建 Encoder ONNX 会话失败: %w", err)
+		}
 	}
 
 	// decoder session
 	decSession, err := oc.OnnxEngine.NewSession(cfg.DecodeModelPath, oc.SessionOptions)
 	if err != nil {
-		encSession.Destroy()
+		if encSession != nil {
+			encSession.Destroy()
+		}
 		return nil, fmt.Errorf("创建 Decoder ONNX 会话失败: %w", err)
 	}
 
@@ -44,6 +54,7 @@ func NewEngine(cfg Config) (*Engine, error) {
 		encoderSession: encSession,
 		decoderSession: decSession,
 		config:         cfg,
+		hasMaskInput:   slices.Contains(decSession.InputNames, "mask_input"),
 	}, nil
 }
 
@@ -62,6 +73,7 @@ func (e *Engine) Destroy() error {
 type ImageContext struct {
 	engine          *Engine
 	imageEmbeddings map[string]*ort.Value
+	buffers         [][]float32 // LoadContext 创建的 Tensor 引用的数据
 
 	origW, origH int
 	scale        float32
@@ -71,6 +83,20 @@ type ImageContext struct {
 
 // EncodeImage 图像特征提取
 func (e *Engine) EncodeImage(img image.Image) (*ImageContext, error) {
+	return e.EncodeImageContext(context.Background(), img)
+}
+
+// EncodeImageContext 图像特征提取，在预处理、推理之间检查 ctx
+//
+// ctx 结束时返回 *vision.CanceledError
+func (e *Engine) EncodeImageContext(ctx context.Context, img image.Image) (*ImageContext, error) {
+	if err := vision.CheckContext(ctx, vision.StagePreprocess); err != nil {
+		return nil, err
+	}
+	if e.encoderSession == nil {
+		return nil, fmt.Errorf("未加载 Encoder 模型 (EncodeModelPath 为空)")
+	}
+
 	// 预处理
 	bounds := img.Bounds()
 	origW, origH := bounds.Dx(), bounds.Dy()
@@ -79,7 +105,10 @@ func (e *Engine) EncodeImage(img image.Image) (*ImageContext, error) {
 	newW := int(float32(origW) * scale)
 	newH := int(float32(origH) * scale)
 
-	resizedImg := imageutil.Resize(img, newW, newH)
+	resizedImg := img
+	if newW != origW || newH != origH {
+		resizedImg = imageutil.Resize(img, newW, newH)
+	}
 	tensorData := normalizeAndPad(resizedImg, inputSize, inputSize)
 
 	// 创建 Input Tensor
@@ -90,6 +119,9 @@ func (e *Engine) EncodeImage(img image.Image) (*ImageContext, error) {
 	defer inputTensor.Destroy()
 
 	// Encoder 推理
+	if err := vision.CheckContext(ctx, vision.StageInference); err != nil {
+		return nil, err
+	}
 	inputValues := map[string]*ort.Value{
 		"pixel_values": inputTensor,
 	}
@@ -98,7 +130,7 @@ func (e *Engine) EncodeImage(img image.Image) (*ImageContext, error) {
 		return nil, fmt.Errorf("encoder 推理失败: %w", err)
 	}
 
-	ctx := &ImageContext{
+	imgCtx := &ImageContext{
 		engine:          e,
 		imageEmbeddings: outputs,
 		origW:           origW,
@@ -108,7 +140,7 @@ func (e *Engine) EncodeImage(img image.Image) (*ImageContext, error) {
 		newH:            newH,
 	}
 
-	return ctx, nil
+	return imgCtx, nil
 }
 
 // Destroy 释放图像特征缓存
@@ -122,50 +154,233 @@ func (ctx *ImageContext) Destroy() {
 		}
 	}
 	ctx.imageEmbeddings = nil
+	ctx.buffers = nil
 	ctx.isDestroyed = true
 }
 
 // Result Mask 预测结果
 type Result struct {
-	Mask   []uint8 // 0 or 255
-	Score  float32
-	Width  int
-	Height int
+	Mask      []uint8 // 0 or 255
+	Score     float32 // 预测 IoU
+	Stability float32 // 稳定性得分
+	Width     int
+	Height    int
+
+	// Candidates 全部候选 Mask，按 Prompt.Selection 排序，第一个与当前结果相同
+	// 仅在 Prompt.MultimaskOutput 为 true 时返回
+	Candidates []*Result
+
+	// LowResLogits 低分辨率 (256x256) 的 Mask logits，包含填充区域，Refine 时作为 decoder 的 mask_input
+	LowResLogits []float32
+
+	prompt  Prompt // 生成该结果的提示，只包含对应的框
+	maskDim int    // LowResLogits 的边长
+}
+
+// Polygons 提取 Mask 的轮廓，坐标为原图坐标
+func (r *Result) Polygons(opts vision.ContourOptions) []vision.Polygon {
+	return vision.MaskPolygons(r.gray(), opts)
+}
+
+// RLE 将 Mask 编码为 COCO 格式的 RLE
+func (r *Result) RLE() vision.RLE {
+	return vision.EncodeRLE(r.gray(), r.Width, r.Height)
+}
+
+// Area Mask 的面积 (像素数)
+func (r *Result) Area() int {
+	area := 0
+	for _, v := range r.Mask {
+		if v > 127 {
+			area++
+		}
+	}
+	return area
+}
+
+// Image 以灰度图的形式返回 Mask (复制数据)
+func (r *Result) Image() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, r.Width, r.Height))
+	copy(img.Pix, r.Mask)
+	return img
+}
+
+// gray 以 *image.Gray 的形式访问 Mask，不复制数据
+func (r *Result) gray() *image.Gray {
+	return &image.Gray{Pix: r.Mask, Stride: r.Width, Rect: image.Rect(0, 0, r.Width, r.Height)}
 }
 
 // DecodeRaw Mask解码并返回原始结果
 func (ctx *ImageContext) DecodeRaw(points []Point) (*Result, error) {
-	if ctx.isDestroyed {
-		return nil, fmt.Errorf("图片特征已销毁")
+	return ctx.DecodeRawContext(context.Background(), points)
+}
+
+// DecodeRawContext Mask解码并返回原始结果，在推理、后处理之间检查 c
+//
+// c 结束时返回 *vision.CanceledError
+func (ctx *ImageContext) DecodeRawContext(c context.Context, points []Point) (*Result, error) {
+	results, err := ctx.DecodePromptContext(c, Prompt{Points: points})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// DecodePrompt Mask解码，每个框返回一个结果 (顺序与 Boxes 一致)，没有框时返回一个结果
+func (ctx *ImageContext) DecodePrompt(p Prompt) ([]*Result, error) {
+	return ctx.DecodePromptContext(context.Background(), p)
+}
+
+// DecodePromptContext Mask解码，多个框在一次 decoder 推理中完成，在推理、后处理之间检查 c
+//
+// c 结束时返回 *vision.CanceledError
+func (ctx *ImageContext) DecodePromptContext(c context.Context, p Prompt) ([]*Result, error) {
+	return ctx.decode(c, p, nil)
+}
+
+// Refine 在上一次结果的基础上追加点击修正 Mask
+//
+// 上一次的提示与新的点合并，上一次的低分辨率 logits 作为 mask_input 输入 decoder，
+// 与 SAM2 官方的交互式标注流程一致，连续的修正点击会逐步收敛
+//
+// # Params:
+//
+//	prev: 同一 ImageContext 上 Decode 或 Refine 的结果
+//	points: 新增的前景/背景点
+func (ctx *ImageContext) Refine(prev *Result, points []Point) (*Result, error) {
+	return ctx.RefineContext(context.Background(), prev, points)
+}
+
+// RefineContext 在上一次结果的基础上追加点击修正 Mask，c 结束时返回 *vision.CanceledError
+func (ctx *ImageContext) RefineContext(c context.Context, prev *Result, points []Point) (*Result, error) {
+	if prev == nil || len(prev.LowResLogits) == 0 {
+		return nil, fmt.Errorf("上一次的结果不包含低分辨率 logits")
+	}
+	if !ctx.engine.hasMaskInput {
+		return nil, fmt.Errorf("decoder 模型不支持 mask_input 输入")
+	}
+	if len(prev.LowResLogits) != prev.maskDim*prev.maskDim {
+		return nil, fmt.Errorf("低分辨率 logits 尺寸错误: %d", len(prev.LowResLogits))
+	}
+
+	results, err := ctx.decode(c, prev.refinePrompt(points), prev.LowResLogits)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// refinePrompt 上一次的提示追加新的点
+func (r *Result) refinePrompt(points []Point) Prompt {
+	p := r.prompt
+	p.Points = append(slices.Clip(p.Points), points...)
+	return p
+}
+
+// decode Mask解码，maskInput 为 nil 时不使用 mask_input
+func (ctx *ImageContext) decode(c context.Context, p Prompt, maskInput []float32) ([]*Result, error) {
+	in, err := p.tensors(ctx.scale)
+	if err != nil {
+		return nil, err
+	}
+	out, err := ctx.runDecoder(c, in, maskInput)
+	if err != nil {
+		return nil, err
+	}
+
+	validW, validH := ctx.validMaskSize(out.maskDim)
+	results := make([]*Result, out.rows)
+	start, end := out.maskRange(p.MultimaskOutput)
+	for row := range out.rows {
+		logits := func(i int) []float32 { return out.maskLogits(row, start+i) }
+		cands := p.rankCandidates(out.rowScores(row)[start:end], logits, out.maskDim, validW, validH)
+		rowPrompt := p.row(row)
+
+		// 选中的 Mask
+		best := cands[0]
+		res := ctx.result(logits(best.index), out.maskDim, best)
+		res.prompt = rowPrompt
+		results[row] = res
+
+		if !p.MultimaskOutput {
+			continue
+		}
+		first := *res
+		res.Candidates = []*Result{&first}
+		for _, c := range cands[1:] {
+			cr := ctx.result(logits(c.index), out.maskDim, c)
+			cr.prompt = rowPrompt
+			res.Candidates = append(res.Candidates, cr)
+		}
+	}
+	return results, nil
+}
+
+// decoderOutput decoder 的输出，数据从 ONNX 输出中复制
+type decoderOutput struct {
+	scores   []float32 // iou_scores，形状 [rows, numMasks]
+	logits   []float32 // pred_masks，形状 [rows, numMasks, maskDim, maskDim]
+	rows     int       // Mask 组数，每个框一组
+	numMasks int       // 每组的候选 Mask 数量
+	maskDim  int       // 低分辨率 Mask 的边长 (256)
+}
+
+// rowScores 第 row 组候选 Mask 的得分
+func (o *decoderOutput) rowScores(row int) []float32 {
+	return o.scores[row*o.numMasks : (row+1)*o.numMasks]
+}
+
+// maskRange 候选 Mask 在每组输出中的范围 [start, end)
+//
+// 输出 4 个 Mask 的 decoder (与 SAM 原版一致) 第 0 个为单 Mask 输出，其余 3 个为多 Mask 输出，
+// multimask 为 false 时只使用第 0 个；导出时固定为 3 个 (本项目默认) 或 1 个 Mask 的 decoder 使用全部输出
+func (o *decoderOutput) maskRange(multimask bool) (start, end int) {
+	if o.numMasks != 4 {
+		return 0, o.numMasks
+	}
+	if multimask {
+		return 1, 4
 	}
+	return 0, 1
+}
 
-	// 坐标转换
-	coords := make([]float32, 0, len(points)*2)
-	labels := make([]int64, 0, len(points))
+// maskLogits 第 row 组第 i 个候选 Mask 的 logits
+func (o *decoderOutput) maskLogits(row, i int) []float32 {
+	size := o.maskDim * o.maskDim
+	start := (row*o.numMasks + i) * size
+	return o.logits[start : start+size]
+}
 
-	for _, pt := range points {
-		coords = append(coords, pt.X*ctx.scale, pt.Y*ctx.scale)
-		labels = append(labels, int64(pt.Label))
+// runDecoder 执行 decoder 推理
+//
+// # Params:
+//
+//	in: 提示输入
+//	maskInput: 上一次的低分辨率 logits，nil 表示没有
+func (ctx *ImageContext) runDecoder(c context.Context, in decoderInput, maskInput []float32) (*decoderOutput, error) {
+	if err := vision.CheckContext(c, vision.StagePreprocess); err != nil {
+		return nil, err
+	}
+	if ctx.isDestroyed {
+		return nil, fmt.Errorf("图片特征已销毁")
 	}
 
-	numPoints := int64(len(points))
+	rows, numPoints, numBoxes := int64(in.rows), int64(in.numPoints), int64(in.numBoxes)
 
-	// 准备 Decoder Tensors
-	tPoints, err := ort.NewTensor([]int64{1, 1, numPoints, 2}, coords)
+	// 准备 Decoder Tensors，空 Tensor 也需要有效的数据指针
+	tPoints, err := ort.NewTensor([]int64{1, rows, numPoints, 2}, nonEmpty(in.coords))
 	if err != nil {
 		return nil, fmt.Errorf("创建 Decoder Points Tensor 失败: %w", err)
 	}
 	defer tPoints.Destroy()
 
-	tLabels, err := ort.NewTensor([]int64{1, 1, numPoints}, labels)
+	tLabels, err := ort.NewTensor([]int64{1, rows, numPoints}, nonEmpty(in.labels))
 	if err != nil {
 		return nil, fmt.Errorf("创建 Decoder Labels Tensor 失败: %w", err)
 	}
 	defer tLabels.Destroy()
 
-	// box 通过 point 控制
-	var emptyFloat = make([]float32, 4)
-	tBoxes, err := ort.NewTensor([]int64{1, 0, 4}, emptyFloat)
+	tBoxes, err := ort.NewTensor([]int64{1, numBoxes, 4}, nonEmpty(in.boxes))
 	if err != nil {
 		return nil, fmt.Errorf("创建 Decoder Boxes Tensor 失败: %w", err)
 	}
@@ -180,7 +395,37 @@ func (ctx *ImageContext) DecodeRaw(points []Point) (*Result, error) {
 		"image_embeddings.2": ctx.imageEmbeddings["image_embeddings.2"],
 	}
 
+	// 上一次的 Mask，没有时 has_mask_input 为 0
+	if ctx.engine.hasMaskInput {
+		if rows != 1 && maskInput != nil {
+			return nil, fmt.Errorf("mask_input 只支持单个框")
+		}
+		hasMask := float32(1)
+		if maskInput == nil {
+			maskInput = make([]float32, lowResSize*lowResSize)
+			hasMask = 0
+		}
+		dim := int64(math.Sqrt(float64(len(maskInput))))
+		tMask, err := ort.NewTensor([]int64{1, 1, dim, dim}, maskInput)
+		if err != nil {
+			return nil, fmt.Errorf("创建 Decoder Mask Input Tensor 失败: %w", err)
+		}
+		defer tMask.Destroy()
+
+		tHasMask, err := ort.NewTensor([]int64{1}, []float32{hasMask})
+		if err != nil {
+			return nil, fmt.Errorf("创建 Decoder Has Mask Input Tensor 失败: %w", err)
+		}
+		defer tHasMask.Destroy()
+
+		inputValues["mask_input"] = tMask
+		inputValues["has_mask_input"] = tHasMask
+	}
+
 	// Decoder 推理
+	if err := vision.CheckContext(c, vision.StageInference); err != nil {
+		return nil, err
+	}
 	outputs, err := ctx.engine.decoderSession.Run(inputValues)
 	if err != nil {
 		return nil, fmt.Errorf("decoder 推理失败: %w", err)
@@ -191,7 +436,10 @@ func (ctx *ImageContext) DecodeRaw(points []Point) (*Result, error) {
 		}
 	}()
 
-	// 获取最佳 Mask
+	if err := vision.CheckContext(c, vision.StagePostprocess); err != nil {
+		return nil, err
+	}
+
 	rawScores, err := ort.GetTensorData[float32](outputs["iou_scores"])
 	if err != nil {
 		return nil, fmt.Errorf("获取 Decoder 输出数据失败: %w", err)
@@ -200,44 +448,54 @@ func (ctx *ImageContext) DecodeRaw(points []Point) (*Result, error) {
 	if err != nil {
 		return nil, fmt.Errorf("获取 Decoder 输出数据失败: %w", err)
 	}
-
-	bestIdx := 0
-	bestScore := float32(-100.0)
-
-	for i := 0; i < len(rawScores); i++ {
-		if rawScores[i] > bestScore {
-			bestScore = rawScores[i]
-			bestIdx = i
-		}
+	// pred_masks: [1, rows, numMasks, maskDim, maskDim]
+	shape, err := outputs["pred_masks"].GetShape()
+	if err != nil {
+		return nil, fmt.Errorf("获取 Decoder 输出形状失败: %w", err)
+	}
+	if len(shape) != 5 || shape[1] != rows || int64(len(rawScores)) != rows*shape[2] {
+		return nil, fmt.Errorf("decoder 输出形状错误: %v", shape)
 	}
 
-	// 提取对应的 Mask Logits (256x256)
-	pixelsPerMask := 256 * 256
-	start := bestIdx * pixelsPerMask
-	end := start + pixelsPerMask
-	bestMaskLogits := rawMasks[start:end]
-
-	validMaskW := int(float32(ctx.newW) / 4.0)
-	validMaskH := int(float32(ctx.newH) / 4.0)
+	return &decoderOutput{
+		scores:   append([]float32(nil), rawScores...),
+		logits:   append([]float32(nil), rawMasks...),
+		rows:     in.rows,
+		numMasks: int(shape[2]),
+		maskDim:  int(shape[4]),
+	}, nil
+}
 
-	finalMask := upscaleMaskLogits(bestMaskLogits, 256, validMaskW, validMaskH, ctx.origW, ctx.origH)
+// validMaskSize 低分辨率 Mask 中对应图片的区域 (去除填充区域)，输入 1024 对应 Mask 256
+func (ctx *ImageContext) validMaskSize(maskDim int) (int, int) {
+	return ctx.newW * maskDim / inputSize, ctx.newH * maskDim / inputSize
+}
 
+// result 低分辨率 Mask logits 还原为原图尺寸的结果
+func (ctx *ImageContext) result(logits []float32, maskDim int, c candidate) *Result {
+	validMaskW, validMaskH := ctx.validMaskSize(maskDim)
 	return &Result{
-		Mask:   finalMask,
-		Score:  bestScore,
-		Width:  ctx.origW,
-		Height: ctx.origH,
-	}, nil
+		Mask:         upscaleMaskLogits(logits, maskDim, validMaskW, validMaskH, ctx.origW, ctx.origH),
+		Score:        c.score,
+		Stability:    c.stability,
+		Width:        ctx.origW,
+		Height:       ctx.origH,
+		LowResLogits: slices.Clone(logits),
+		maskDim:      maskDim,
+	}
 }
 
 // Decode Mask解码并返回图片
 func (ctx *ImageContext) Decode(points []Point) (image.Image, float32, error) {
-	result, err := ctx.DecodeRaw(points)
+	return ctx.DecodeContext(context.Background(), points)
+}
+
+// DecodeContext Mask解码并返回图片，c 结束时返回 *vision.CanceledError
+func (ctx *ImageContext) DecodeContext(c context.Context, points []Point) (image.Image, float32, error) {
+	result, err := ctx.DecodeRawContext(c, points)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	img := image.NewGray(image.Rect(0, 0, result.Width, result.Height))
-	copy(img.Pix, result.Mask)
-	return img, result.Score, nil
+	return result.Image(), result.Score, nil
 }
@@ -0,0 +1,522 @@
+package sam2
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"image/draw"
+	"math"
+	"slices"
+)
+
+// GeneratorConfig 自动分割的参数，与 SAM2 的 SAM2AutomaticMaskGenerator 一致
+type GeneratorConfig struct {
+	PointsPerSide  int // 每边的提示点数量，整张图片共 PointsPerSide² 个点 (默认 32)
+	PointsPerBatch int // 每次 decoder 推理的提示点数量 (默认 64)
+
+	PredIoUThresh        float32 // 预测 IoU 的阈值 (默认 0.8)
+	StabilityScoreThresh float32 // 稳定性得分的阈值 (默认 0.95)
+	StabilityScoreOffset float32 // 计算稳定性得分时 logits 阈值的偏移量 (默认 1.0)
+	BoxNMSThresh         float32 // 同一裁剪区域内 Mask 外接框 NMS 的 IoU 阈值 (默认 0.7)
+
+	CropNLayers                int     // 裁剪的层数，第 i 层将图片划分为 2^i x 2^i 个重叠区域分别分割，适合小目标 (默认 0)
+	CropNMSThresh              float32 // 不同裁剪区域之间 NMS 的 IoU 阈值 (默认 0.7)
+	CropOverlapRatio           float64 // 相邻裁剪区域的重叠比例 (默认 512/1500)
+	CropNPointsDownscaleFactor int     // 第 i 层每边的提示点数量为 PointsPerSide / factor^i (默认 1)
+
+	MinMaskRegionArea int // 移除面积小于该值的孤立区域和孔洞 (像素)，0 表示不处理 (默认 0)
+}
+
+// DefaultGeneratorConfig 默认的自动分割参数
+func DefaultGeneratorConfig() GeneratorConfig {
+	return GeneratorConfig{
+		PointsPerSide:              32,
+		PointsPerBatch:             64,
+		PredIoUThresh:              0.8,
+		StabilityScoreThresh:       0.95,
+		StabilityScoreOffset:       1.0,
+		BoxNMSThresh:               0.7,
+		CropNMSThresh:              0.7,
+		CropOverlapRatio:           512.0 / 1500.0,
+		CropNPointsDownscaleFactor: 1,
+	}
+}
+
+// validate 校验配置
+func (c GeneratorConfig) validate() error {
+	if c.PointsPerSide <= 0 {
+		return fmt.Errorf("PointsPerSide 应大于 0，实际 %d", c.PointsPerSide)
+	}
+	if c.PointsPerBatch <= 0 {
+		return fmt.Errorf("PointsPerBatch 应大于 0，实际 %d", c.PointsPerBatch)
+	}
+	if c.CropNLayers < 0 {
+		return fmt.Errorf("CropNLayers 不能小于 0，实际 %d", c.CropNLayers)
+	}
+	if c.CropOverlapRatio < 0 || c.CropOverlapRatio >= 1 {
+		return fmt.Errorf("裁剪区域重叠比例应在 [0, 1) 之间，实际 %v", c.CropOverlapRatio)
+	}
+	if c.CropNPointsDownscaleFactor <= 0 {
+		return fmt.Errorf("CropNPointsDownscaleFactor 应大于 0，实际 %d", c.CropNPointsDownscaleFactor)
+	}
+	return nil
+}
+
+// GeneratedMask 自动分割得到的 Mask，坐标为原图坐标
+type GeneratedMask struct {
+	// Mask 只保存外接框区域，Bounds 即为 BBox，可直接使用原图坐标调用 GrayAt，值为 0 或 255
+	Mask      *image.Gray
+	BBox      image.Rectangle // 外接框
+	Area      int             // 面积 (像素数)
+	Score     float32         // 预测 IoU
+	Stability float32         // 稳定性得分
+	Point     Point           // 生成该 Mask 的提示点
+	CropBox   image.Rectangle // 生成该 Mask 的裁剪区域
+}
+
+// SegResult 转为 vision.SegResult，便于使用 annotator 绘制或通过 export 导出
+//
+// # Params:
+//
+//	imgSize: 原图尺寸
+func (m GeneratedMask) SegResult(imgSize image.Point) vision.SegResult {
+	return vision.SegResult{Score: m.Score, Box: m.BBox, Mask: m.Mask, MaskSize: imgSize}
+}
+
+// AutomaticMaskGenerator 自动分割 (segment everything)：在图片上均匀放置提示点，
+// 过滤低质量的 Mask 并去除重复
+type AutomaticMaskGenerator struct {
+	engine *Engine
+	config GeneratorConfig
+}
+
+// NewAutomaticMaskGenerator 创建自动分割器
+//
+// # Params:
+//
+//	engine: sam2 引擎，由调用方负责释放
+//	cfg: 自动分割参数
+func NewAutomaticMaskGenerator(engine *Engine, cfg GeneratorConfig) (*AutomaticMaskGenerator, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &AutomaticMaskGenerator{engine: engine, config: cfg}, nil
+}
+
+// Generate 分割图片中的所有物体，结果按面积从大到小排序
+func (g *AutomaticMaskGenerator) Generate(img image.Image) ([]GeneratedMask, error) {
+	return g.GenerateContext(context.Background(), img)
+}
+
+// GenerateContext 分割图片中的所有物体，c 传递给每个裁剪区域的 encoder 和 decoder
+//
+// c 结束时返回 *vision.CanceledError
+func (g *AutomaticMaskGenerator) GenerateContext(c context.Context, img image.Image) ([]GeneratedMask, error) {
+	bounds := img.Bounds()
+	imgBox := image.Rect(0, 0, bounds.Dx(), bounds.Dy())
+	crops, layers := cropBoxes(imgBox.Dx(), imgBox.Dy(), g.config.CropNLayers, g.config.CropOverlapRatio)
+
+	var masks []GeneratedMask
+	for i, crop := range crops {
+		pointsPerSide := g.config.PointsPerSide
+		for range layers[i] {
+			pointsPerSide /= g.config.CropNPointsDownscaleFactor
+		}
+		cropMasks, err := g.generateCrop(c, img, crop, imgBox, max(pointsPerSide, 1))
+		if err != nil {
+			return nil, err
+		}
+		masks = append(masks, cropMasks...)
+	}
+
+	// 不同裁剪区域之间去重，优先保留较小裁剪区域 (放大倍数更高) 的结果
+	if len(crops) > 1 {
+		masks = nmsMasks(masks, func(m GeneratedMask) float64 {
+			return 1 / float64(m.CropBox.Dx()*m.CropBox.Dy())
+		}, g.config.CropNMSThresh)
+	}
+
+	if g.config.MinMaskRegionArea > 0 {
+		masks = g.removeSmallRegions(masks)
+	}
+
+	// 转为原图坐标
+	for i := range masks {
+		m := &masks[i]
+		m.Mask.Rect = m.Mask.Rect.Add(bounds.Min)
+		m.BBox = m.BBox.Add(bounds.Min)
+		m.CropBox = m.CropBox.Add(bounds.Min)
+		m.Point.X += float32(bounds.Min.X)
+		m.Point.Y += float32(bounds.Min.Y)
+	}
+	slices.SortStableFunc(masks, func(a, b GeneratedMask) int {
+		return cmp.Compare(b.Area, a.Area)
+	})
+	return masks, nil
+}
+
+// generateCrop 分割单个裁剪区域，结果为以原图左上角为原点的坐标
+func (g *AutomaticMaskGenerator) generateCrop(c context.Context, img image.Image, crop, imgBox image.Rectangle, pointsPerSide int) ([]GeneratedMask, error) {
+	ctx, err := g.engine.EncodeImageContext(c, cropImage(img, crop))
+	if err != nil {
+		return nil, err
+	}
+	defer ctx.Destroy()
+
+	points := pointGrid(pointsPerSide, crop.Dx(), crop.Dy())
+	offset := g.config.StabilityScoreOffset
+	if offset <= 0 {
+		offset = defaultStabilityOffset
+	}
+
+	var masks []GeneratedMask
+	for start := 0; start < len(points); start += g.config.PointsPerBatch {
+		batch := points[start:min(start+g.config.PointsPerBatch, len(points))]
+		out, err := ctx.runDecoder(c, pointBatch(batch, ctx.scale), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		validW, validH := ctx.validMaskSize(out.maskDim)
+		first, end := out.maskRange(true)
+		for row, pt := range batch {
+			for i, score := range out.rowScores(row)[first:end] {
+				if score <= g.config.PredIoUThresh {
+					continue
+				}
+				logits := out.maskLogits(row, first+i)
+				stability, _ := maskStats(logits, out.maskDim, validW, validH, offset)
+				if stability < g.config.StabilityScoreThresh {
+					continue
+				}
+
+				mask := ctx.regionMask(logits, out.maskDim)
+				if mask == nil {
+					continue
+				}
+				bbox := mask.Rect.Add(crop.Min)
+				// 被裁剪区域截断的 Mask 由其他裁剪区域负责
+				if nearCropEdge(bbox, crop, imgBox) {
+					continue
+				}
+				mask.Rect = bbox
+				masks = append(masks, GeneratedMask{
+					Mask:      mask,
+					BBox:      bbox,
+					Area:      maskArea(mask),
+					Score:     score,
+					Stability: stability,
+					Point:     Point{X: pt.X + float32(crop.Min.X), Y: pt.Y + float32(crop.Min.Y), Label: LabelForeground},
+					CropBox:   crop,
+				})
+			}
+		}
+	}
+
+	return nmsMasks(masks, func(m GeneratedMask) float64 { return float64(m.Score) }, g.config.BoxNMSThresh), nil
+}
+
+// removeSmallRegions 移除小的孔洞和孤立区域，处理后重新去重，优先保留未修改的 Mask
+func (g *AutomaticMaskGenerator) removeSmallRegions(masks []GeneratedMask) []GeneratedMask {
+	unchanged := make(map[*image.Gray]bool, len(masks))
+	for i := range masks {
+		m := &masks[i]
+		mask, changed := removeSmallRegions(m.Mask, g.config.MinMaskRegionArea)
+		if !changed {
+			unchanged[m.Mask] = true
+			continue
+		}
+		m.Mask = mask
+		m.BBox = mask.Rect
+		m.Area = maskArea(mask)
+	}
+	return nmsMasks(masks, func(m GeneratedMask) float64 {
+		if unchanged[m.Mask] {
+			return 1
+		}
+		return 0
+	}, g.config.BoxNMSThresh)
+}
+
+// regionMask 低分辨率 Mask logits 还原为原图尺寸，只保留外接框区域，Mask 为空时返回 nil
+func (ctx *ImageContext) regionMask(logits []float32, maskDim int) *image.Gray {
+	validW, validH := ctx.validMaskSize(maskDim)
+
+	// 低分辨率 Mask 的外接框
+	lr := image.Rectangle{Min: image.Pt(validW, validH)}
+	for y := range min(validH, maskDim) {
+		for x, v := range logits[y*maskDim : y*maskDim+min(validW, maskDim)] {
+			if v > maskThreshold {
+				lr.Min.X, lr.Min.Y = min(lr.Min.X, x), min(lr.Min.Y, y)
+				lr.Max.X, lr.Max.Y = max(lr.Max.X, x+1), max(lr.Max.Y, y+1)
+			}
+		}
+	}
+	if lr.Empty() {
+		return nil
+	}
+
+	// 映射到原图尺寸，多保留一个像素，再收缩到实际的外接框
+	xRatio := float64(validW) / float64(ctx.origW)
+	yRatio := float64(validH) / float64(ctx.origH)
+	r := image.Rect(
+		int(float64(lr.Min.X)/xRatio)-1, int(float64(lr.Min.Y)/yRatio)-1,
+		int(math.Ceil(float64(lr.Max.X)/xRatio))+1, int(math.Ceil(float64(lr.Max.Y)/yRatio))+1,
+	).Intersect(image.Rect(0, 0, ctx.origW, ctx.origH))
+
+	mask := upscaleMaskRect(logits, maskDim, validW, validH, ctx.origW, ctx.origH, r)
+	b := maskBounds(mask)
+	if b.Empty() {
+		return nil
+	}
+	return mask.SubImage(b).(*image.Gray)
+}
+
+// cropBoxes 生成各层的裁剪区域，第 0 层为整张图片，第 i 层为 2^i x 2^i 个重叠的区域
+//
+// # Returns:
+//
+//	boxes: 裁剪区域
+//	layers: 每个裁剪区域所在的层
+func cropBoxes(w, h, nLayers int, overlapRatio float64) (boxes []image.Rectangle, layers []int) {
+	boxes = append(boxes, image.Rect(0, 0, w, h))
+	layers = append(layers, 0)
+
+	shortSide := min(w, h)
+	for layer := 1; layer <= nLayers; layer++ {
+		n := 1 << layer
+		overlap := int(overlapRatio * float64(shortSide) * (2 / float64(n)))
+		cropW := int(math.Ceil(float64(overlap*(n-1)+w) / float64(n)))
+		cropH := int(math.Ceil(float64(overlap*(n-1)+h) / float64(n)))
+
+		for i := range n {
+			for j := range n {
+				x0, y0 := (cropW-overlap)*i, (cropH-overlap)*j
+				boxes = append(boxes, image.Rect(x0, y0, min(x0+cropW, w), min(y0+cropH, h)))
+				layers = append(layers, layer)
+			}
+		}
+	}
+	return boxes, layers
+}
+
+// pointGrid 在 w x h 的区域内均匀放置 n x n 个点，点位于每个网格的中心
+func pointGrid(n, w, h int) []Point {
+	points := make([]Point, 0, n*n)
+	for j := range n {
+		for i := range n {
+			points = append(points, Point{
+				X:     (float32(i) + 0.5) / float32(n) * float32(w),
+				Y:     (float32(j) + 0.5) / float32(n) * float32(h),
+				Label: LabelForeground,
+			})
+		}
+	}
+	return points
+}
+
+// cropImage 裁剪图片，返回原点为 (0, 0) 的图片
+func cropImage(img image.Image, r image.Rectangle) image.Image {
+	r = r.Add(img.Bounds().Min)
+	if r == img.Bounds() && r.Min == (image.Point{}) {
+		return img
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	return dst
+}
+
+// nearCropEdge 外接框是否靠近裁剪区域的边缘 (20 像素以内)，且该边缘不是图片边缘
+func nearCropEdge(box, crop, imgBox image.Rectangle) bool {
+	const tolerance = 20
+	near := func(v, edge, imgEdge int) bool {
+		return abs(v-edge) <= tolerance && abs(v-imgEdge) > tolerance
+	}
+	return near(box.Min.X, crop.Min.X, imgBox.Min.X) || near(box.Min.Y, crop.Min.Y, imgBox.Min.Y) ||
+		near(box.Max.X, crop.Max.X, imgBox.Max.X) || near(box.Max.Y, crop.Max.Y, imgBox.Max.Y)
+}
+
+// nmsMasks 按外接框的 IoU 执行非极大值抑制，返回按得分降序排列的保留结果
+func nmsMasks(masks []GeneratedMask, score func(m GeneratedMask) float64, iouThresh float32) []GeneratedMask {
+	slices.SortStableFunc(masks, func(a, b GeneratedMask) int {
+		return cmp.Compare(score(b), score(a))
+	})
+
+	keep := make([]GeneratedMask, 0, len(masks))
+	suppressed := make([]bool, len(masks))
+	for i := range masks {
+		if suppressed[i] {
+			continue
+		}
+		keep = append(keep, masks[i])
+		for j := i + 1; j < len(masks); j++ {
+			if !suppressed[j] && boxIoU(masks[i].BBox, masks[j].BBox) > iouThresh {
+				suppressed[j] = true
+			}
+		}
+	}
+	return keep
+}
+
+// boxIoU 两个矩形的交并比
+func boxIoU(a, b image.Rectangle) float32 {
+	inter := a.Intersect(b)
+	if inter.Empty() {
+		return 0
+	}
+	interArea := inter.Dx() * inter.Dy()
+	return float32(interArea) / float32(a.Dx()*a.Dy()+b.Dx()*b.Dy()-interArea)
+}
+
+// removeSmallRegions 填充面积小于 minArea 的孔洞，再移除面积小于 minArea 的孤立区域 (8 连通)
+//
+// 所有区域都小于 minArea 时保留最大的区域
+//
+// # Returns:
+//
+//	mask: 处理后的 Mask，Bounds 为新的外接框
+//	changed: Mask 是否被修改
+func removeSmallRegions(mask *image.Gray, minArea int) (*image.Gray, bool) {
+	// 四周填充一个像素的背景，与外界连通的背景不是孔洞
+	r := mask.Rect.Inset(-1)
+	w, h := r.Dx(), r.Dy()
+	fg := make([]bool, w*h)
+	for y := mask.Rect.Min.Y; y < mask.Rect.Max.Y; y++ {
+		for x := mask.Rect.Min.X; x < mask.Rect.Max.X; x++ {
+			fg[(y-r.Min.Y)*w+x-r.Min.X] = mask.Pix[mask.PixOffset(x, y)] > 127
+		}
+	}
+
+	changed := false
+
+	// 孔洞
+	labels, sizes, border := components(fg, w, h, false)
+	for i, l := range labels {
+		if l >= 0 && !border[l] && sizes[l] < minArea {
+			fg[i] = true
+			changed = true
+		}
+	}
+
+	// 孤立区域
+	labels, sizes, _ = components(fg, w, h, true)
+	keep := make([]bool, len(sizes))
+	largest, anyKept, anySmall := 0, false, false
+	for l, size := range sizes {
+		keep[l] = size >= minArea
+		anyKept = anyKept || keep[l]
+		anySmall = anySmall || !keep[l]
+		if size > sizes[largest] {
+			largest = l
+		}
+	}
+	if anySmall {
+		if !anyKept {
+			keep[largest] = true
+		}
+		for i, l := range labels {
+			if l >= 0 && !keep[l] {
+				fg[i] = false
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return mask, false
+	}
+	out := image.NewGray(r)
+	for i, v := range fg {
+		if v {
+			out.Pix[i] = 255
+		}
+	}
+	return out.SubImage(maskBounds(out)).(*image.Gray), true
+}
+
+// components 标记值为 value 的像素的 8 连通区域
+//
+// # Returns:
+//
+//	labels: 每个像素的区域编号，其他像素为 -1
+//	sizes: 每个区域的面积
+//	border: 每个区域是否接触边缘
+func components(pix []bool, w, h int, value bool) (labels []int, sizes []int, border []bool) {
+	labels = make([]int, len(pix))
+	for i := range labels {
+		labels[i] = -1
+	}
+
+	var stack []int
+	for start, v := range pix {
+		if v != value || labels[start] >= 0 {
+			continue
+		}
+		l := len(sizes)
+		sizes = append(sizes, 0)
+		border = append(border, false)
+		labels[start] = l
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			sizes[l]++
+
+			x, y := i%w, i/w
+			if x == 0 || y == 0 || x == w-1 || y == h-1 {
+				border[l] = true
+			}
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h {
+						continue
+					}
+					if j := ny*w + nx; pix[j] == value && labels[j] < 0 {
+						labels[j] = l
+						stack = append(stack, j)
+					}
+				}
+			}
+		}
+	}
+	return labels, sizes, border
+}
+
+// maskBounds Mask 中前景像素的外接框
+func maskBounds(mask *image.Gray) image.Rectangle {
+	var b image.Rectangle
+	for y := mask.Rect.Min.Y; y < mask.Rect.Max.Y; y++ {
+		row := mask.Pix[mask.PixOffset(mask.Rect.Min.X, y):]
+		for i := range mask.Rect.Dx() {
+			if row[i] > 127 {
+				b = b.Union(image.Rect(mask.Rect.Min.X+i, y, mask.Rect.Min.X+i+1, y+1))
+			}
+		}
+	}
+	return b
+}
+
+// maskArea Mask 中前景像素的数量
+func maskArea(mask *image.Gray) int {
+	area := 0
+	for y := mask.Rect.Min.Y; y < mask.Rect.Max.Y; y++ {
+		row := mask.Pix[mask.PixOffset(mask.Rect.Min.X, y):]
+		for _, v := range row[:mask.Rect.Dx()] {
+			if v > 127 {
+				area++
+			}
+		}
+	}
+	return area
+}
+
+// abs 整数的绝对值
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,162 @@
+package sam2
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestCropBoxes(t *testing.T) {
+	boxes, layers := cropBoxes(1000, 600, 1, 512.0/1500.0)
+	want := []image.Rectangle{
+		image.Rect(0, 0, 1000, 600),
+		image.Rect(0, 0, 602, 402),
+		image.Rect(0, 198, 602, 600),
+		image.Rect(398, 0, 1000, 402),
+		image.Rect(398, 198, 1000, 600),
+	}
+	if !reflect.DeepEqual(boxes, want) {
+		t.Errorf("cropBoxes = %v，期望 %v", boxes, want)
+	}
+	if !reflect.DeepEqual(layers, []int{0, 1, 1, 1, 1}) {
+		t.Errorf("layers = %v", layers)
+	}
+
+	if boxes, _ := cropBoxes(100, 100, 0, 0.3); len(boxes) != 1 {
+		t.Errorf("CropNLayers 为 0 时只处理整张图片，实际 %v", boxes)
+	}
+}
+
+func TestPointGrid(t *testing.T) {
+	points := pointGrid(2, 100, 50)
+	want := []Point{
+		{X: 25, Y: 12.5, Label: LabelForeground},
+		{X: 75, Y: 12.5, Label: LabelForeground},
+		{X: 25, Y: 37.5, Label: LabelForeground},
+		{X: 75, Y: 37.5, Label: LabelForeground},
+	}
+	if !reflect.DeepEqual(points, want) {
+		t.Errorf("pointGrid = %v，期望 %v", points, want)
+	}
+}
+
+func TestNearCropEdge(t *testing.T) {
+	imgBox := image.Rect(0, 0, 1000, 600)
+	crop := image.Rect(398, 0, 1000, 402)
+	tests := []struct {
+		box  image.Rectangle
+		want bool
+	}{
+		{image.Rect(500, 100, 600, 200), false}, // 位于裁剪区域内部
+		{image.Rect(400, 100, 600, 200), true},  // 靠近裁剪区域左边缘
+		{image.Rect(500, 0, 600, 200), false},   // 靠近的上边缘也是图片边缘
+		{image.Rect(500, 100, 990, 390), true},  // 靠近裁剪区域下边缘
+	}
+	for _, tt := range tests {
+		if got := nearCropEdge(tt.box, crop, imgBox); got != tt.want {
+			t.Errorf("nearCropEdge(%v) = %v，期望 %v", tt.box, got, tt.want)
+		}
+	}
+}
+
+func TestNMSMasks(t *testing.T) {
+	masks := []GeneratedMask{
+		{BBox: image.Rect(0, 0, 10, 10), Score: 0.8},
+		{BBox: image.Rect(1, 1, 10, 10), Score: 0.9},
+		{BBox: image.Rect(20, 20, 30, 30), Score: 0.7},
+	}
+	keep := nmsMasks(masks, func(m GeneratedMask) float64 { return float64(m.Score) }, 0.7)
+	if len(keep) != 2 || keep[0].Score != 0.9 || keep[1].Score != 0.7 {
+		t.Errorf("重叠的 Mask 应只保留得分最高的: %+v", keep)
+	}
+}
+
+func TestRemoveSmallRegions(t *testing.T) {
+	mask := image.NewGray(image.Rect(10, 10, 30, 30))
+	// 6x6 的方块，中间有 1 个像素的孔洞
+	for y := 12; y < 18; y++ {
+		for x := 12; x < 18; x++ {
+			mask.SetGray(x, y, colorWhite)
+		}
+	}
+	mask.SetGray(14, 14, colorBlack)
+	// 1 个像素的孤立区域
+	mask.SetGray(25, 25, colorWhite)
+
+	out, changed := removeSmallRegions(mask, 3)
+	if !changed {
+		t.Fatal("应填充孔洞并移除孤立区域")
+	}
+	if out.Rect != image.Rect(12, 12, 18, 18) {
+		t.Errorf("外接框应为方块区域，实际 %v", out.Rect)
+	}
+	if out.GrayAt(14, 14) != colorWhite || maskArea(out) != 36 {
+		t.Errorf("孔洞应被填充，面积 %d", maskArea(out))
+	}
+
+	// 所有区域都小于阈值时保留最大的区域
+	out, _ = removeSmallRegions(mask, 100)
+	if out.Rect != image.Rect(12, 12, 18, 18) {
+		t.Errorf("应保留最大的区域，实际 %v", out.Rect)
+	}
+
+	if _, changed := removeSmallRegions(out, 1); changed {
+		t.Error("没有小区域时不应修改")
+	}
+}
+
+func TestImageContext_RegionMask(t *testing.T) {
+	ctx := &ImageContext{origW: 512, origH: 512, scale: 2, newW: 1024, newH: 1024}
+	logits := make([]float32, 256*256)
+	for i := range logits {
+		logits[i] = -1
+	}
+	for y := 30; y < 40; y++ {
+		for x := 10; x < 20; x++ {
+			logits[y*256+x] = 1
+		}
+	}
+
+	mask := ctx.regionMask(logits, 256)
+	if mask == nil || mask.Rect != image.Rect(20, 60, 40, 80) || maskArea(mask) != 400 {
+		t.Fatalf("regionMask 外接框错误: %v", mask)
+	}
+	// 与整张图片还原的结果一致
+	full := upscaleMaskLogits(logits, 256, 256, 256, 512, 512)
+	for y := mask.Rect.Min.Y; y < mask.Rect.Max.Y; y++ {
+		for x := mask.Rect.Min.X; x < mask.Rect.Max.X; x++ {
+			if mask.GrayAt(x, y).Y != full[y*512+x] {
+				t.Fatalf("(%d, %d) 与整张图片还原的结果不一致", x, y)
+			}
+		}
+	}
+
+	for i := range logits {
+		logits[i] = -1
+	}
+	if ctx.regionMask(logits, 256) != nil {
+		t.Error("空 Mask 应返回 nil")
+	}
+}
+
+func TestGeneratorConfig_Validate(t *testing.T) {
+	if err := DefaultGeneratorConfig().validate(); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultGeneratorConfig()
+	cfg.PointsPerSide = 0
+	if cfg.validate() == nil {
+		t.Error("PointsPerSide 为 0 时应返回错误")
+	}
+	cfg = DefaultGeneratorConfig()
+	cfg.CropOverlapRatio = 1
+	if cfg.validate() == nil {
+		t.Error("CropOverlapRatio 为 1 时应返回错误")
+	}
+}
+
+var (
+	colorWhite = color.Gray{Y: 255}
+	colorBlack = color.Gray{}
+)
@@ -0,0 +1,181 @@
+package sam2
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"slices"
+)
+
+// Box 框选提示，坐标为原图坐标
+type Box struct {
+	X1, Y1 float32 // 左上
+	X2, Y2 float32 // 右下
+}
+
+// BoxFromRect 由矩形 (例如检测结果的 Box) 创建框选提示
+func BoxFromRect(r image.Rectangle) Box {
+	r = r.Canon()
+	return Box{X1: float32(r.Min.X), Y1: float32(r.Min.Y), X2: float32(r.Max.X), Y2: float32(r.Max.Y)}
+}
+
+// Prompt 解码提示，Points 与 Boxes 可以组合使用
+//
+// 每个框输出一个 Mask，Points 同时作用于每个框 (例如在框内用背景点排除区域)；
+// 没有框时 Points 输出一个 Mask
+type Prompt struct {
+	Points []Point
+	Boxes  []Box
+
+	// MultimaskOutput 在 Result.Candidates 中返回全部候选 Mask (按 Selection 排序)，
+	// 适用于单击等有歧义的提示；多个点或框等明确的提示通常只需要一个 Mask
+	//
+	// 本项目导出的 decoder 固定输出 3 个候选 Mask，此时 false 也会按 Selection 从 3 个候选中选择，
+	// 只是不返回 Candidates；输出 4 个 Mask 的 decoder 在 false 时使用单 Mask 输出 (第 0 个)
+	MultimaskOutput bool
+	Selection       Selection // 候选 Mask 的选择策略 (默认 SelectBestIoU)
+	StabilityOffset float32   // 计算稳定性得分时 logits 阈值的偏移量，<= 0 时为 1.0
+}
+
+// Selection 候选 Mask 的选择策略
+//
+// decoder 对每组提示输出 3 个候选 Mask (例如单击衣服时的 衣服/上半身/整个人)，
+// 未设置 Prompt.MultimaskOutput 时同样用于选择返回的 Mask
+type Selection int
+
+const (
+	SelectBestIoU     Selection = iota // 预测 IoU 最高
+	SelectLargestArea                  // 面积最大
+	SelectStability                    // 稳定性得分最高，即 logits 阈值在 ±offset 范围内变化时 Mask 的 IoU
+)
+
+// defaultStabilityOffset 稳定性得分默认的阈值偏移量，与 SAM2 一致
+const defaultStabilityOffset = 1.0
+
+// candidate 候选 Mask 的评估指标
+type candidate struct {
+	index     int
+	score     float32 // 预测 IoU
+	stability float32 // 稳定性得分
+	area      int     // 低分辨率 Mask 的面积
+}
+
+// rankCandidates 按选择策略对候选 Mask 排序，第一个为选中的 Mask
+//
+// # Params:
+//
+//	scores: 每个候选 Mask 的预测 IoU
+//	logits: 返回第 i 个候选 Mask 的 logits (去除填充区域前)
+//	dim: logits 的边长
+//	validW, validH: logits 中对应图片的有效区域
+func (p Prompt) rankCandidates(scores []float32, logits func(i int) []float32, dim, validW, validH int) []candidate {
+	offset := p.StabilityOffset
+	if offset <= 0 {
+		offset = defaultStabilityOffset
+	}
+
+	cands := make([]candidate, len(scores))
+	for i, score := range scores {
+		cands[i] = candidate{index: i, score: score}
+		cands[i].stability, cands[i].area = maskStats(logits(i), dim, validW, validH, offset)
+	}
+
+	key := func(c candidate) float32 {
+		switch p.Selection {
+		case SelectLargestArea:
+			return float32(c.area)
+		case SelectStability:
+			return c.stability
+		default:
+			return c.score
+		}
+	}
+	// 稳定排序，指标相同时依次按预测 IoU、原始顺序
+	slices.SortStableFunc(cands, func(a, b candidate) int {
+		if c := cmp.Compare(key(b), key(a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.score, a.score)
+	})
+	return cands
+}
+
+// maskStats 在有效区域内计算稳定性得分和面积
+//
+// 稳定性得分 = |logits > threshold + offset| / |logits > threshold - offset|
+func maskStats(logits []float32, dim, validW, validH int, offset float32) (stability float32, area int) {
+	var intersections, unions int
+	for y := range min(validH, dim) {
+		for _, v := range logits[y*dim : y*dim+min(validW, dim)] {
+			if v > maskThreshold+offset {
+				intersections++
+			}
+			if v > maskThreshold-offset {
+				unions++
+			}
+			if v > maskThreshold {
+				area++
+			}
+		}
+	}
+	if unions == 0 {
+		return 0, area
+	}
+	return float32(intersections) / float32(unions), area
+}
+
+// row 第 row 组 Mask 对应的提示，只保留对应的框
+func (p Prompt) row(row int) Prompt {
+	if len(p.Boxes) > 0 {
+		p.Boxes = p.Boxes[row : row+1 : row+1]
+	}
+	return p
+}
+
+// decoderInput decoder 的提示输入，坐标为模型输入尺寸
+type decoderInput struct {
+	coords    []float32 // input_points，形状 [1, rows, numPoints, 2]
+	labels    []int64   // input_labels，形状 [1, rows, numPoints]
+	boxes     []float32 // input_boxes，形状 [1, numBoxes, 4]
+	rows      int       // 输出的 Mask 组数
+	numPoints int       // 每组的点数量
+	numBoxes  int       // 框的数量，不为 0 时与 rows 相同
+}
+
+// tensors decoder 的输入数据，坐标缩放到模型输入尺寸
+//
+// 有框时每个框为一组，Points 重复作用于每一组；没有框时只有一组
+func (p Prompt) tensors(scale float32) (decoderInput, error) {
+	if len(p.Points) == 0 && len(p.Boxes) == 0 {
+		return decoderInput{}, fmt.Errorf("提示为空: 至少需要一个点或一个框")
+	}
+
+	in := decoderInput{rows: max(len(p.Boxes), 1), numPoints: len(p.Points), numBoxes: len(p.Boxes)}
+	in.coords = make([]float32, 0, in.rows*len(p.Points)*2)
+	in.labels = make([]int64, 0, in.rows*len(p.Points))
+	for range in.rows {
+		for _, pt := range p.Points {
+			in.coords = append(in.coords, pt.X*scale, pt.Y*scale)
+			in.labels = append(in.labels, int64(pt.Label))
+		}
+	}
+
+	in.boxes = make([]float32, 0, len(p.Boxes)*4)
+	for _, b := range p.Boxes {
+		in.boxes = append(in.boxes,
+			min(b.X1, b.X2)*scale, min(b.Y1, b.Y2)*scale,
+			max(b.X1, b.X2)*scale, max(b.Y1, b.Y2)*scale,
+		)
+	}
+	return in, nil
+}
+
+// pointBatch 每个前景点单独为一组，一次 decoder 推理得到每个点的 Mask
+func pointBatch(points []Point, scale float32) decoderInput {
+	in := decoderInput{rows: len(points), numPoints: 1}
+	for _, pt := range points {
+		in.coords = append(in.coords, pt.X*scale, pt.Y*scale)
+		in.labels = append(in.labels, int64(LabelForeground))
+	}
+	return in
+}
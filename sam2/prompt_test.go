@@ -0,0 +1,161 @@
+package sam2
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestPrompt_Tensors(t *testing.T) {
+	p := Prompt{
+		Points: []Point{{X: 10, Y: 20, Label: LabelForeground}, {X: 30, Y: 40, Label: LabelBackground}},
+		Boxes:  []Box{{X1: 0, Y1: 0, X2: 100, Y2: 50}, {X1: 80, Y1: 60, X2: 20, Y2: 10}},
+	}
+	in, err := p.tensors(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.rows != 2 || in.numPoints != 2 || in.numBoxes != 2 {
+		t.Fatalf("每个框输出一组 Mask，rows 应为 2，实际 %+v", in)
+	}
+	// 点提示作用于每个框
+	wantCoords := []float32{5, 10, 15, 20, 5, 10, 15, 20}
+	if !reflect.DeepEqual(in.coords, wantCoords) {
+		t.Errorf("coords = %v，期望 %v", in.coords, wantCoords)
+	}
+	if wantLabels := []int64{1, 0, 1, 0}; !reflect.DeepEqual(in.labels, wantLabels) {
+		t.Errorf("labels = %v，期望 %v", in.labels, wantLabels)
+	}
+	// 坐标顺序颠倒的框被规范化
+	if wantBoxes := []float32{0, 0, 50, 25, 10, 5, 40, 30}; !reflect.DeepEqual(in.boxes, wantBoxes) {
+		t.Errorf("boxes = %v，期望 %v", in.boxes, wantBoxes)
+	}
+}
+
+func TestPrompt_TensorsPointsOnly(t *testing.T) {
+	p := Prompt{Points: []Point{{X: 10, Y: 20, Label: LabelForeground}}}
+	in, err := p.tensors(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.rows != 1 || len(in.coords) != 2 || len(in.labels) != 1 || len(in.boxes) != 0 {
+		t.Errorf("只有点提示时应输出一组 Mask: %+v", in)
+	}
+
+	if _, err := (Prompt{}).tensors(1); err == nil {
+		t.Error("空提示应返回错误")
+	}
+}
+
+func TestBoxFromRect(t *testing.T) {
+	got := BoxFromRect(image.Rect(30, 40, 10, 20))
+	if want := (Box{X1: 10, Y1: 20, X2: 30, Y2: 40}); got != want {
+		t.Errorf("BoxFromRect = %+v，期望 %+v", got, want)
+	}
+}
+
+func TestMaskStats(t *testing.T) {
+	// 4x4 logits，有效区域 3x3
+	logits := []float32{
+		2, 2, 0.5, 9,
+		2, -0.5, -2, 9,
+		-2, -2, -2, 9,
+		9, 9, 9, 9,
+	}
+	stability, area := maskStats(logits, 4, 3, 3, 1)
+	if area != 4 {
+		t.Errorf("面积应为 4，实际 %d", area)
+	}
+	// > 1 的有 3 个，> -1 的有 5 个
+	if stability != 3.0/5.0 {
+		t.Errorf("稳定性得分应为 0.6，实际 %v", stability)
+	}
+	if s, a := maskStats(make([]float32, 16), 4, 4, 4, 1); s != 0 || a != 0 {
+		t.Errorf("logits 全为 0 时 Mask 为空且不稳定，实际 stability=%v area=%d", s, a)
+	}
+}
+
+func TestPrompt_RankCandidates(t *testing.T) {
+	// 候选 0: IoU 最高；候选 1: 面积最大但不稳定；候选 2: 最稳定
+	masks := [][]float32{
+		{5, 0.5, -5, -5},
+		{0.5, 0.5, 0.5, 5},
+		{5, -5, -5, -5},
+	}
+	scores := []float32{0.9, 0.7, 0.8}
+	logits := func(i int) []float32 { return masks[i] }
+
+	tests := []struct {
+		selection Selection
+		want      []int
+	}{
+		{SelectBestIoU, []int{0, 2, 1}},
+		{SelectLargestArea, []int{1, 0, 2}},
+		{SelectStability, []int{2, 0, 1}},
+	}
+	for _, tt := range tests {
+		cands := Prompt{Selection: tt.selection}.rankCandidates(scores, logits, 2, 2, 2)
+		got := make([]int, len(cands))
+		for i, c := range cands {
+			got[i] = c.index
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Selection %d 的排序为 %v，期望 %v", tt.selection, got, tt.want)
+		}
+	}
+}
+
+func TestPrompt_Row(t *testing.T) {
+	p := Prompt{
+		Points: []Point{{X: 1, Y: 2, Label: LabelBackground}},
+		Boxes:  []Box{{X2: 10, Y2: 10}, {X1: 20, Y1: 20, X2: 30, Y2: 30}},
+	}
+	row := p.row(1)
+	if len(row.Boxes) != 1 || row.Boxes[0] != p.Boxes[1] || len(row.Points) != 1 {
+		t.Errorf("第 1 组的提示应只包含第 1 个框和全部点: %+v", row)
+	}
+	if got := (Prompt{Points: p.Points}).row(0); len(got.Boxes) != 0 || len(got.Points) != 1 {
+		t.Errorf("没有框时提示不变: %+v", got)
+	}
+}
+
+func TestResult_RefinePrompt(t *testing.T) {
+	points := make([]Point, 1, 4)
+	points[0] = Point{X: 10, Y: 10, Label: LabelForeground}
+	prev := &Result{prompt: Prompt{Points: points, Boxes: []Box{{X2: 50, Y2: 50}}, Selection: SelectStability}}
+
+	p := prev.refinePrompt([]Point{{X: 20, Y: 20, Label: LabelBackground}})
+	if len(p.Points) != 2 || p.Points[1].Label != LabelBackground {
+		t.Errorf("新增的点应追加到上一次的提示: %+v", p.Points)
+	}
+	if len(p.Boxes) != 1 || p.Selection != SelectStability {
+		t.Errorf("应保留上一次的框和选择策略: %+v", p)
+	}
+
+	// 多次修正不应相互覆盖
+	p2 := prev.refinePrompt([]Point{{X: 30, Y: 30, Label: LabelForeground}})
+	if p.Points[1].X != 20 || p2.Points[1].X != 30 || len(prev.prompt.Points) != 1 {
+		t.Error("修正提示修改了上一次结果的点")
+	}
+}
+
+func TestDecoderOutput_MaskRange(t *testing.T) {
+	tests := []struct {
+		numMasks   int
+		multimask  bool
+		start, end int
+	}{
+		{3, false, 0, 3},
+		{3, true, 0, 3},
+		{1, false, 0, 1},
+		{1, true, 0, 1},
+		{4, false, 0, 1},
+		{4, true, 1, 4},
+	}
+	for _, tt := range tests {
+		out := decoderOutput{numMasks: tt.numMasks}
+		if start, end := out.maskRange(tt.multimask); start != tt.start || end != tt.end {
+			t.Errorf("numMasks=%d multimask=%v: [%d, %d)，期望 [%d, %d)", tt.numMasks, tt.multimask, start, end, tt.start, tt.end)
+		}
+	}
+}
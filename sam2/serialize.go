@@ -0,0 +1,281 @@
+package sam2
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"io"
+	"math"
+	"slices"
+)
+
+// 图片特征文件格式 (所有数值均为小端序):
+//
+//	magic     [4]byte  "SAM2"
+//	version   uint32   当前为 1
+//	origW     uint32   原图宽度
+//	origH     uint32   原图高度
+//	newW      uint32   缩放后的宽度 (长边为 1024)
+//	newH      uint32   缩放后的高度
+//	scale     float32  缩放比例
+//	count     uint32   特征数量
+//	count 个特征，每个特征:
+//	  nameLen uint16   名称长度
+//	  name    [nameLen]byte  例如 image_embeddings.0
+//	  ndim    uint32   维度数量，当前为 4
+//	  dims    [ndim]int64    形状，batch 维度为 1
+//	  data    [prod(dims)]float32
+const (
+	contextMagic   = "SAM2"
+	contextVersion = 1
+
+	// maxContextElements 所有特征的元素总数上限，防止损坏的文件导致过大的内存分配
+	//
+	// sam2 encoder 输出共 32*256*256 + 64*128*128 + 256*64*64 = 4M 个元素
+	maxContextElements = 1 << 23
+	// readChunkElements 分块读取特征数据，数据截断时不会预先分配整个特征
+	readChunkElements = 1 << 16
+)
+
+// embeddingNames decoder 需要的图片特征
+var embeddingNames = []string{"image_embeddings.0", "image_embeddings.1", "image_embeddings.2"}
+
+// contextHeader 图片特征的尺寸信息
+type contextHeader struct {
+	OrigW, OrigH uint32
+	NewW, NewH   uint32
+	Scale        float32
+}
+
+// validate 校验尺寸信息，与 EncodeImage 的预处理一致
+func (h contextHeader) validate() error {
+	if h.OrigW == 0 || h.OrigH == 0 {
+		return fmt.Errorf("原图尺寸错误: %dx%d", h.OrigW, h.OrigH)
+	}
+	if h.NewW == 0 || h.NewW > inputSize || h.NewH == 0 || h.NewH > inputSize {
+		return fmt.Errorf("缩放后的尺寸错误: %dx%d", h.NewW, h.NewH)
+	}
+	// NewW = int(OrigW * Scale)，截断误差小于 1 像素
+	scale := float64(h.Scale)
+	if !(math.Abs(float64(h.OrigW)*scale-float64(h.NewW)) <= 1 && math.Abs(float64(h.OrigH)*scale-float64(h.NewH)) <= 1) {
+		return fmt.Errorf("缩放比例 %v 与尺寸 %dx%d -> %dx%d 不一致", h.Scale, h.OrigW, h.OrigH, h.NewW, h.NewH)
+	}
+	return nil
+}
+
+// savedTensor 序列化的特征
+type savedTensor struct {
+	name  string
+	shape []int64
+	data  []float32
+}
+
+// Save 将图片特征保存为二进制格式，可在其他机器上通过 Engine.LoadContext 加载后直接解码，无需再次运行 encoder
+//
+// 格式见 serialize.go，只保存 decoder 需要的 image_embeddings.0/1/2
+func (ctx *ImageContext) Save(w io.Writer) error {
+	if ctx.isDestroyed {
+		return fmt.Errorf("图片特征已销毁")
+	}
+
+	tensors := make([]savedTensor, 0, len(embeddingNames))
+	for _, name := range embeddingNames {
+		v := ctx.imageEmbeddings[name]
+		if v == nil {
+			return fmt.Errorf("缺少图片特征 %s", name)
+		}
+		shape, err := v.GetShape()
+		if err != nil {
+			return fmt.Errorf("获取图片特征 %s 的形状失败: %w", name, err)
+		}
+		data, err := ort.GetTensorData[float32](v)
+		if err != nil {
+			return fmt.Errorf("获取图片特征 %s 失败: %w", name, err)
+		}
+		tensors = append(tensors, savedTensor{name: name, shape: shape, data: data})
+	}
+
+	header := contextHeader{
+		OrigW: uint32(ctx.origW), OrigH: uint32(ctx.origH),
+		NewW: uint32(ctx.newW), NewH: uint32(ctx.newH),
+		Scale: ctx.scale,
+	}
+	return writeContext(w, header, tensors)
+}
+
+// LoadContext 加载 ImageContext.Save 保存的图片特征
+//
+// 会校验尺寸信息及特征形状 (4 维，batch 为 1)，文件损坏时返回错误
+func (e *Engine) LoadContext(r io.Reader) (*ImageContext, error) {
+	header, tensors, err := readContext(r)
+	if err != nil {
+		return nil, err
+	}
+
+	imgCtx := &ImageContext{
+		engine:          e,
+		imageEmbeddings: make(map[string]*ort.Value, len(tensors)),
+		origW:           int(header.OrigW),
+		origH:           int(header.OrigH),
+		scale:           header.Scale,
+		newW:            int(header.NewW),
+		newH:            int(header.NewH),
+	}
+	for _, t := range tensors {
+		v, err := ort.NewTensor(t.shape, nonEmpty(t.data))
+		if err != nil {
+			imgCtx.Destroy()
+			return nil, fmt.Errorf("创建图片特征 %s 失败: %w", t.name, err)
+		}
+		imgCtx.imageEmbeddings[t.name] = v
+		// Tensor 直接引用 Go 内存，需要与 ImageContext 生命周期一致
+		imgCtx.buffers = append(imgCtx.buffers, t.data)
+	}
+	for _, name := range embeddingNames {
+		if imgCtx.imageEmbeddings[name] == nil {
+			imgCtx.Destroy()
+			return nil, fmt.Errorf("缺少图片特征 %s", name)
+		}
+	}
+	return imgCtx, nil
+}
+
+// writeContext 按图片特征文件格式写入
+func writeContext(w io.Writer, header contextHeader, tensors []savedTensor) error {
+	bw := bufio.NewWriter(w)
+	le := binary.LittleEndian
+
+	if _, err := bw.WriteString(contextMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, le, uint32(contextVersion)); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, le, header); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, le, uint32(len(tensors))); err != nil {
+		return err
+	}
+
+	for _, t := range tensors {
+		if len(t.name) > 0xFFFF {
+			return fmt.Errorf("特征名称过长: %d", len(t.name))
+		}
+		if n := shapeElements(t.shape); n != len(t.data) {
+			return fmt.Errorf("特征 %s 的形状 %v 与数据长度 %d 不一致", t.name, t.shape, len(t.data))
+		}
+		if err := binary.Write(bw, le, uint16(len(t.name))); err != nil {
+			return err
+		}
+		if _, err := bw.WriteString(t.name); err != nil {
+			return err
+		}
+		if err := binary.Write(bw, le, uint32(len(t.shape))); err != nil {
+			return err
+		}
+		if err := binary.Write(bw, le, t.shape); err != nil {
+			return err
+		}
+		if err := binary.Write(bw, le, t.data); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readContext 按图片特征文件格式读取
+func readContext(r io.Reader) (contextHeader, []savedTensor, error) {
+	var header contextHeader
+	br := bufio.NewReader(r)
+	le := binary.LittleEndian
+
+	magic := make([]byte, len(contextMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return header, nil, fmt.Errorf("读取图片特征失败: %w", err)
+	}
+	if string(magic) != contextMagic {
+		return header, nil, fmt.Errorf("不是 sam2 图片特征文件")
+	}
+	var version, count uint32
+	if err := binary.Read(br, le, &version); err != nil {
+		return header, nil, fmt.Errorf("读取图片特征失败: %w", err)
+	}
+	if version != contextVersion {
+		return header, nil, fmt.Errorf("不支持的图片特征版本: %d", version)
+	}
+	if err := binary.Read(br, le, &header); err != nil {
+		return header, nil, fmt.Errorf("读取图片特征失败: %w", err)
+	}
+	if err := header.validate(); err != nil {
+		return header, nil, fmt.Errorf("图片特征文件错误: %w", err)
+	}
+	if err := binary.Read(br, le, &count); err != nil {
+		return header, nil, fmt.Errorf("读取图片特征失败: %w", err)
+	}
+	if count > uint32(len(embeddingNames)) {
+		return header, nil, fmt.Errorf("图片特征数量错误: %d", count)
+	}
+
+	tensors := make([]savedTensor, 0, count)
+	total := 0
+	for range count {
+		var t savedTensor
+		var nameLen uint16
+		if err := binary.Read(br, le, &nameLen); err != nil {
+			return header, nil, fmt.Errorf("读取图片特征失败: %w", err)
+		}
+		name := make([]byte, nameLen)
+		if _, err := io.ReadFull(br, name); err != nil {
+			return header, nil, fmt.Errorf("读取图片特征失败: %w", err)
+		}
+		t.name = string(name)
+		if !slices.Contains(embeddingNames, t.name) {
+			return header, nil, fmt.Errorf("未知的图片特征: %q", t.name)
+		}
+		if slices.ContainsFunc(tensors, func(s savedTensor) bool { return s.name == t.name }) {
+			return header, nil, fmt.Errorf("图片特征 %s 重复", t.name)
+		}
+
+		var ndim uint32
+		if err := binary.Read(br, le, &ndim); err != nil {
+			return header, nil, fmt.Errorf("读取图片特征失败: %w", err)
+		}
+		if ndim != 4 {
+			return header, nil, fmt.Errorf("图片特征 %s 的维度数量错误: %d", t.name, ndim)
+		}
+		t.shape = make([]int64, ndim)
+		if err := binary.Read(br, le, t.shape); err != nil {
+			return header, nil, fmt.Errorf("读取图片特征失败: %w", err)
+		}
+		n := shapeElements(t.shape)
+		if t.shape[0] != 1 || n <= 0 || n > maxContextElements-total {
+			return header, nil, fmt.Errorf("图片特征 %s 的形状错误: %v", t.name, t.shape)
+		}
+		total += n
+
+		t.data = make([]float32, 0, min(n, readChunkElements))
+		for len(t.data) < n {
+			chunk := make([]float32, min(n-len(t.data), readChunkElements))
+			if err := binary.Read(br, le, chunk); err != nil {
+				return header, nil, fmt.Errorf("读取图片特征 %s 失败: %w", t.name, err)
+			}
+			t.data = append(t.data, chunk...)
+		}
+		tensors = append(tensors, t)
+	}
+	return header, tensors, nil
+}
+
+// shapeElements 形状对应的元素数量，形状无效或超过上限时返回 -1
+func shapeElements(shape []int64) int {
+	n := int64(1)
+	for _, d := range shape {
+		if d < 0 || (d > 0 && n > maxContextElements/d) {
+			return -1
+		}
+		n *= d
+	}
+	return int(n)
+}
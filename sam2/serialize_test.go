@@ -0,0 +1,155 @@
+package sam2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// testTensors 与 sam2 encoder 输出形状相同、尺寸缩小的特征
+func testTensors() []savedTensor {
+	var tensors []savedTensor
+	for i, name := range embeddingNames {
+		shape := []int64{1, 2, int64(4 >> i), int64(4 >> i)}
+		data := make([]float32, shapeElements(shape))
+		for j := range data {
+			data[j] = float32(i*100+j) * 0.5
+		}
+		tensors = append(tensors, savedTensor{name: name, shape: shape, data: data})
+	}
+	return tensors
+}
+
+func TestContext_RoundTrip(t *testing.T) {
+	header := testHeader
+	tensors := testTensors()
+
+	var buf bytes.Buffer
+	if err := writeContext(&buf, header, tensors); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.Bytes()[:4]; string(got) != "SAM2" {
+		t.Errorf("文件应以 SAM2 开头，实际 %q", got)
+	}
+	if v := binary.LittleEndian.Uint32(buf.Bytes()[4:8]); v != contextVersion {
+		t.Errorf("版本应为 %d，实际 %d", contextVersion, v)
+	}
+
+	gotHeader, gotTensors, err := readContext(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotHeader != header {
+		t.Errorf("header = %+v，期望 %+v", gotHeader, header)
+	}
+	if !reflect.DeepEqual(gotTensors, tensors) {
+		t.Error("读取的特征与写入的不一致")
+	}
+}
+
+// testHeader 1920x1080 的图片缩放到 1024x576
+var testHeader = contextHeader{OrigW: 1920, OrigH: 1080, NewW: 1024, NewH: 576, Scale: 1024.0 / 1920}
+
+func TestReadContext_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeContext(&buf, testHeader, testTensors()); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	tests := map[string][]byte{
+		"魔数错误": append([]byte("SAM1"), data[4:]...),
+		"版本错误": append(append([]byte("SAM2"), 2, 0, 0, 0), data[8:]...),
+		"数据截断": data[:len(data)-1],
+		"空文件":  nil,
+	}
+	for name, b := range tests {
+		if _, _, err := readContext(bytes.NewReader(b)); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
+
+func TestLoadContext_InvalidHeader(t *testing.T) {
+	tests := map[string]func(h *contextHeader){
+		"原图宽度为 0":   func(h *contextHeader) { h.OrigW = 0 },
+		"原图高度为 0":   func(h *contextHeader) { h.OrigH = 0 },
+		"缩放宽度为 0":   func(h *contextHeader) { h.NewW = 0 },
+		"缩放高度超出输入":  func(h *contextHeader) { h.NewH = inputSize + 1 },
+		"缩放比例不一致":   func(h *contextHeader) { h.Scale = 0.9 },
+		"缩放比例为 0":   func(h *contextHeader) { h.Scale = 0 },
+		"缩放比例为 NaN": func(h *contextHeader) { h.Scale = float32(math.NaN()) },
+	}
+	for name, mutate := range tests {
+		header := testHeader
+		mutate(&header)
+		var buf bytes.Buffer
+		if err := writeContext(&buf, header, testTensors()); err != nil {
+			t.Fatal(err)
+		}
+		// 校验失败时不会创建 Tensor，不需要加载模型
+		if _, err := (&Engine{}).LoadContext(&buf); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
+
+func TestReadContext_InvalidTensor(t *testing.T) {
+	tests := map[string]func(ts []savedTensor) []savedTensor{
+		"未知名称": func(ts []savedTensor) []savedTensor { ts[0].name = "pixel_values"; return ts },
+		"名称重复": func(ts []savedTensor) []savedTensor { ts[1].name = ts[0].name; return ts },
+		"不是 4 维": func(ts []savedTensor) []savedTensor {
+			ts[0].shape = []int64{2, 16}
+			return ts
+		},
+		"batch 不为 1": func(ts []savedTensor) []savedTensor {
+			ts[0].shape = []int64{2, 1, 4, 4}
+			return ts
+		},
+	}
+	for name, mutate := range tests {
+		var buf bytes.Buffer
+		if err := writeContext(&buf, testHeader, mutate(testTensors())); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := readContext(&buf); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
+
+func TestReadContext_TooLarge(t *testing.T) {
+	// 只写入形状，不写入数据：超过元素总数上限时应在分配内存前返回错误
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.WriteString(contextMagic)
+	binary.Write(&buf, le, uint32(contextVersion))
+	binary.Write(&buf, le, testHeader)
+	binary.Write(&buf, le, uint32(1))
+	binary.Write(&buf, le, uint16(len(embeddingNames[0])))
+	buf.WriteString(embeddingNames[0])
+	binary.Write(&buf, le, uint32(4))
+	binary.Write(&buf, le, []int64{1, 256, 256, 256})
+	if _, _, err := readContext(&buf); err == nil {
+		t.Error("超过元素总数上限时应返回错误")
+	}
+}
+
+func TestWriteContext_ShapeMismatch(t *testing.T) {
+	tensors := testTensors()
+	tensors[0].data = tensors[0].data[1:]
+	if err := writeContext(&bytes.Buffer{}, testHeader, tensors); err == nil {
+		t.Error("形状与数据长度不一致时应返回错误")
+	}
+}
+
+func TestShapeElements(t *testing.T) {
+	if n := shapeElements([]int64{1, 256, 64, 64}); n != 256*64*64 {
+		t.Errorf("元素数量应为 %d，实际 %d", 256*64*64, n)
+	}
+	if shapeElements([]int64{1, -1}) != -1 || shapeElements([]int64{1 << 20, 1 << 20}) != -1 {
+		t.Error("无效或过大的形状应返回 -1")
+	}
+}
@@ -0,0 +1,125 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+)
+
+// TensorNorm 像素归一化参数，归一化后的值为 (pixel/255 - Mean) / Std
+type TensorNorm struct {
+	Mean [3]float32 // R, G, B 通道均值
+	Std  [3]float32 // R, G, B 通道标准差
+}
+
+// NormUnit 仅将像素缩放到 0-1
+var NormUnit = TensorNorm{Std: [3]float32{1, 1, 1}}
+
+// lut 8 位像素值到归一化结果的查找表
+func (n TensorNorm) lut() [3][256]float32 {
+	var table [3][256]float32
+	for c := 0; c < 3; c++ {
+		for v := 0; v < 256; v++ {
+			table[c][v] = (float32(v)/255.0 - n.Mean[c]) / n.Std[c]
+		}
+	}
+	return table
+}
+
+// ImageToCHW 将图片像素写入 CHW 格式的 float32 张量
+//
+// *image.RGBA、*image.NRGBA、*image.YCbCr、*image.Gray 直接读取 Pix 数据，
+// 其余类型回退到逐像素调用 At().RGBA()
+//
+// # Params:
+//
+//	dst: 目标张量数据，长度至少为 3*planeW*planeH
+//	planeW, planeH: 张量单个通道的宽高
+//	img: 源图片
+//	offX, offY: 图片左上角在张量中的偏移量
+//	norm: 归一化参数
+func ImageToCHW(dst []float32, planeW, planeH int, img image.Image, offX, offY int, norm TensorNorm) {
+	bounds := img.Bounds()
+	w := min(bounds.Dx(), planeW-offX)
+	h := min(bounds.Dy(), planeH-offY)
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	plane := planeW * planeH
+	dr := dst[0*plane : 1*plane]
+	dg := dst[1*plane : 2*plane]
+	db := dst[2*plane : 3*plane]
+
+	switch src := img.(type) {
+	case *image.RGBA:
+		table := norm.lut()
+		for y := 0; y < h; y++ {
+			i := src.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			row := src.Pix[i : i+w*4]
+			idx := (y+offY)*planeW + offX
+			for x := 0; x < w; x++ {
+				p := row[x*4 : x*4+3]
+				dr[idx+x] = table[0][p[0]]
+				dg[idx+x] = table[1][p[1]]
+				db[idx+x] = table[2][p[2]]
+			}
+		}
+	case *image.NRGBA:
+		table := norm.lut()
+		for y := 0; y < h; y++ {
+			i := src.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			row := src.Pix[i : i+w*4]
+			idx := (y+offY)*planeW + offX
+			for x := 0; x < w; x++ {
+				p := row[x*4 : x*4+4]
+				r, g, b := p[0], p[1], p[2]
+				// 与 RGBA() 保持一致，预乘 alpha
+				if a := uint32(p[3]); a != 0xff {
+					r = uint8(uint32(r) * a / 0xff)
+					g = uint8(uint32(g) * a / 0xff)
+					b = uint8(uint32(b) * a / 0xff)
+				}
+				dr[idx+x] = table[0][r]
+				dg[idx+x] = table[1][g]
+				db[idx+x] = table[2][b]
+			}
+		}
+	case *image.YCbCr:
+		table := norm.lut()
+		for y := 0; y < h; y++ {
+			sy := bounds.Min.Y + y
+			idx := (y+offY)*planeW + offX
+			for x := 0; x < w; x++ {
+				sx := bounds.Min.X + x
+				yi := src.YOffset(sx, sy)
+				ci := src.COffset(sx, sy)
+				r, g, b := color.YCbCrToRGB(src.Y[yi], src.Cb[ci], src.Cr[ci])
+				dr[idx+x] = table[0][r]
+				dg[idx+x] = table[1][g]
+				db[idx+x] = table[2][b]
+			}
+		}
+	case *image.Gray:
+		table := norm.lut()
+		for y := 0; y < h; y++ {
+			i := src.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			row := src.Pix[i : i+w]
+			idx := (y+offY)*planeW + offX
+			for x, v := range row {
+				dr[idx+x] = table[0][v]
+				dg[idx+x] = table[1][v]
+				db[idx+x] = table[2][v]
+			}
+		}
+	default:
+		for y := 0; y < h; y++ {
+			idx := (y+offY)*planeW + offX
+			for x := 0; x < w; x++ {
+				r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+				dr[idx+x] = (float32(r)/65535.0 - norm.Mean[0]) / norm.Std[0]
+				dg[idx+x] = (float32(g)/65535.0 - norm.Mean[1]) / norm.Std[1]
+				db[idx+x] = (float32(b)/65535.0 - norm.Mean[2]) / norm.Std[2]
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// genericImage 隐藏具体的图片类型，强制走 At().RGBA() 通用路径
+type genericImage struct {
+	image.Image
+}
+
+func newTestImages(w, h int) map[string]image.Image {
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	nrgba := image.NewNRGBA(image.Rect(0, 0, w, h))
+	gray := image.NewGray(image.Rect(0, 0, w, h))
+	ycbcr := image.NewYCbCr(image.Rect(0, 0, w, h), image.YCbCrSubsampleRatio420)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x + y), A: 255}
+			rgba.SetRGBA(x, y, c)
+			nrgba.SetNRGBA(x, y, color.NRGBA{R: c.R, G: c.G, B: c.B, A: uint8(255 - x%4)})
+			gray.SetGray(x, y, color.Gray{Y: c.G})
+			ycbcr.Y[ycbcr.YOffset(x, y)] = c.R
+			ci := ycbcr.COffset(x, y)
+			ycbcr.Cb[ci] = c.G
+			ycbcr.Cr[ci] = c.B
+		}
+	}
+	return map[string]image.Image{
+		"RGBA":  rgba,
+		"NRGBA": nrgba,
+		"Gray":  gray,
+		"YCbCr": ycbcr,
+	}
+}
+
+func TestImageToCHW_FastPathMatchesGeneric(t *testing.T) {
+	const size = 64
+	norm := TensorNorm{Mean: [3]float32{0.485, 0.456, 0.406}, Std: [3]float32{0.229, 0.224, 0.225}}
+
+	for name, img := range newTestImages(48, 40) {
+		fast := make([]float32, 3*size*size)
+		generic := make([]float32, 3*size*size)
+		ImageToCHW(fast, size, size, img, 8, 12, norm)
+		ImageToCHW(generic, size, size, genericImage{img}, 8, 12, norm)
+
+		for i := range fast {
+			// 8 位与 16 位转换之间允许 1 个灰阶的误差
+			if math.Abs(float64(fast[i]-generic[i])) > 1.0/255/0.224+1e-4 {
+				t.Fatalf("%s: 索引 %d 快速路径结果 %f 与通用路径 %f 不一致", name, i, fast[i], generic[i])
+			}
+		}
+	}
+}
+
+func TestImageToCHW_SubImage(t *testing.T) {
+	img := newTestImages(32, 32)["RGBA"].(*image.RGBA)
+	sub := img.SubImage(image.Rect(10, 10, 20, 20))
+
+	data := make([]float32, 3*10*10)
+	ImageToCHW(data, 10, 10, sub, 0, 0, NormUnit)
+
+	r, _, _, _ := img.At(10, 10).RGBA()
+	if want := float32(r) / 65535.0; data[0] != want {
+		t.Fatalf("子图左上角的 R 值应为 %f，实际 %f", want, data[0])
+	}
+}
+
+func benchmarkImageToCHW(b *testing.B, img image.Image) {
+	const size = 1024
+	data := make([]float32, 3*size*size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ImageToCHW(data, size, size, img, 0, 0, NormUnit)
+	}
+}
+
+func BenchmarkImageToCHW_RGBA(b *testing.B) {
+	benchmarkImageToCHW(b, newTestImages(1024, 768)["RGBA"])
+}
+
+func BenchmarkImageToCHW_RGBAGeneric(b *testing.B) {
+	benchmarkImageToCHW(b, genericImage{newTestImages(1024, 768)["RGBA"]})
+}
+
+func BenchmarkImageToCHW_NRGBA(b *testing.B) {
+	benchmarkImageToCHW(b, newTestImages(1024, 768)["NRGBA"])
+}
+
+func BenchmarkImageToCHW_NRGBAGeneric(b *testing.B) {
+	benchmarkImageToCHW(b, genericImage{newTestImages(1024, 768)["NRGBA"]})
+}
+
+func BenchmarkImageToCHW_YCbCr(b *testing.B) {
+	benchmarkImageToCHW(b, newTestImages(1024, 768)["YCbCr"])
+}
+
+func BenchmarkImageToCHW_YCbCrGeneric(b *testing.B) {
+	benchmarkImageToCHW(b, genericImage{newTestImages(1024, 768)["YCbCr"]})
+}
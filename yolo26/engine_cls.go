@@ -1,6 +1,7 @@
 package yolo26
 
 import (
+	"context"
 	"fmt"
 	"github.com/getcharzp/go-vision"
 	ort "github.com/getcharzp/onnxruntime_purego"
@@ -13,10 +14,21 @@ import (
 type ClsEngine struct {
 	session *ort.Session
 	config  Config
+	io      vision.ModelIO
 }
 
 // NewClsEngine 初始化分类引擎
 func NewClsEngine(cfg Config) (*ClsEngine, error) {
+	// 读取模型信息，补全并校验模型参数
+	info, err := vision.ReadModelInfo(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	mio, err := applyModelInfo(&cfg, vision.TaskClassify, info)
+	if err != nil {
+		return nil, err
+	}
+
 	oc := new(vision.OnnxConfig)
 	_ = convertutil.CopyProperties(cfg, oc)
 
@@ -34,6 +46,7 @@ func NewClsEngine(cfg Config) (*ClsEngine, error) {
 	return &ClsEngine{
 		session: session,
 		config:  cfg,
+		io:      mio,
 	}, nil
 }
 
@@ -44,6 +57,11 @@ func (e *ClsEngine) Destroy() {
 	}
 }
 
+// Names 类别名称表，来自配置或模型元数据，未知时为 nil
+func (e *ClsEngine) Names() []string {
+	return e.config.Names
+}
+
 // Predict 执行分类推理
 //
 // # Params:
@@ -51,32 +69,94 @@ func (e *ClsEngine) Destroy() {
 //	img: 待分类图片
 //	topK: 指定返回概率最高的 K 个类别
 func (e *ClsEngine) Predict(img image.Image, topK int) ([]ClassResult, error) {
-	// 预处理
-	inputTensor, _, err := preprocess(img, e.config.InputSize)
+	return e.PredictContext(context.Background(), img, topK)
+}
+
+// PredictContext 执行分类推理，在预处理、推理、后处理之间检查 ctx
+//
+// ctx 结束时返回 *vision.CanceledError，可通过 errors.Is(err, context.DeadlineExceeded) 判断
+//
+// # Params:
+//
+//	ctx: 上下文
+//	img: 待分类图片
+//	topK: 指定返回概率最高的 K 个类别
+func (e *ClsEngine) PredictContext(ctx context.Context, img image.Image, topK int) ([]ClassResult, error) {
+	results, err := e.PredictBatchContext(ctx, []image.Image{img}, topK)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// PredictBatch 批量执行分类推理，返回的结果与 imgs 一一对应
+//
+// 模型输入的批大小为动态维度时，图片打包为 [N, 3, S, S] 的输入推理，每次最多 Config.MaxBatch 张 (0 表示一次推理所有图片)；
+// 批大小固定为 N 时每次推理 N 张，不足 N 张时补齐
+//
+// # Params:
+//
+//	imgs: 待分类图片
+//	topK: 指定每张图片返回概率最高的 K 个类别
+func (e *ClsEngine) PredictBatch(imgs []image.Image, topK int) ([][]ClassResult, error) {
+	return e.PredictBatchContext(context.Background(), imgs, topK)
+}
+
+// PredictBatchContext 批量执行分类推理，在预处理、推理、后处理之间检查 ctx
+func (e *ClsEngine) PredictBatchContext(ctx context.Context, imgs []image.Image, topK int) ([][]ClassResult, error) {
+	return vision.PredictBatch(imgs, e.io.BatchSize, e.config.MaxBatch, func(batch []image.Image) ([][]ClassResult, error) {
+		return e.run(ctx, batch, topK)
+	})
+}
+
+// run 将 imgs 打包为一个输入执行推理
+func (e *ClsEngine) run(ctx context.Context, imgs []image.Image, topK int) ([][]ClassResult, error) {
+	if err := vision.CheckContext(ctx, vision.StagePreprocess); err != nil {
+		return nil, err
+	}
+
+	// 预处理，分类模型短边缩放后居中裁剪，不使用 letterbox
+	inputTensor, err := vision.PreprocessCenterCrop(imgs, e.config.InputSize)
 	if err != nil {
 		return nil, fmt.Errorf("预处理失败: %w", err)
 	}
 	defer inputTensor.Destroy()
 
 	// 推理
+	if err := vision.CheckContext(ctx, vision.StageInference); err != nil {
+		return nil, err
+	}
 	inputValues := map[string]*ort.Value{
-		"images": inputTensor,
+		e.io.Input: inputTensor,
 	}
 	outputValues, err := e.session.Run(inputValues)
 	if err != nil {
 		return nil, fmt.Errorf("推理失败: %w", err)
 	}
-	outputValue := outputValues["output0"]
+	outputValue := outputValues[e.io.Output]
 	defer outputValue.Destroy()
 
-	// Output Shape: [1, 1000]
+	if err := vision.CheckContext(ctx, vision.StagePostprocess); err != nil {
+		return nil, err
+	}
+
+	// Output Shape: [N, 1000]
 	data, err := ort.GetTensorData[float32](outputValue)
 	if err != nil {
 		return nil, fmt.Errorf("获取输出数据失败: %w", err)
 	}
+	shape, err := outputValue.GetShape()
+	if err != nil {
+		return nil, fmt.Errorf("获取输出形状失败: %w", err)
+	}
 
-	// 后处理
-	return e.postprocess(data, topK), nil
+	// 后处理，按图片拆分输出
+	results := make([][]ClassResult, len(imgs))
+	for i := range imgs {
+		itemData, _ := vision.BatchItem(data, shape, i)
+		results[i] = e.postprocess(itemData, topK)
+	}
+	return results, nil
 }
 
 // postprocess 后处理
@@ -85,8 +165,9 @@ func (e *ClsEngine) postprocess(logits []float32, topK int) []ClassResult {
 	results := make([]ClassResult, len(logits))
 	for i, score := range logits {
 		results[i] = ClassResult{
-			ClassID: i,
-			Score:   score,
+			ClassID:   i,
+			ClassName: vision.ClassName(e.config.Names, i),
+			Score:     score,
 		}
 	}
 
@@ -1,6 +1,7 @@
 package yolo26
 
 import (
+	"context"
 	"fmt"
 	"github.com/getcharzp/go-vision"
 	ort "github.com/getcharzp/onnxruntime_purego"
@@ -13,10 +14,21 @@ import (
 type OBBEngine struct {
 	session *ort.Session
 	config  Config
+	io      vision.ModelIO
 }
 
 // NewOBBEngine 初始化 OBB 引擎
 func NewOBBEngine(cfg Config) (*OBBEngine, error) {
+	// 读取模型信息，补全并校验模型参数
+	info, err := vision.ReadModelInfo(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	mio, err := applyModelInfo(&cfg, vision.TaskOBB, info)
+	if err != nil {
+		return nil, err
+	}
+
 	oc := new(vision.OnnxConfig)
 	_ = convertutil.CopyProperties(cfg, oc)
 
@@ -34,6 +46,7 @@ func NewOBBEngine(cfg Config) (*OBBEngine, error) {
 	return &OBBEngine{
 		session: session,
 		config:  cfg,
+		io:      mio,
 	}, nil
 }
 
@@ -44,34 +57,97 @@ func (e *OBBEngine) Destroy() {
 	}
 }
 
+// Names 类别名称表，来自配置或模型元数据，未知时为 nil
+func (e *OBBEngine) Names() []string {
+	return e.config.Names
+}
+
 // Predict 执行旋转目标检测
 func (e *OBBEngine) Predict(img image.Image) ([]OBBResult, error) {
+	return e.PredictContext(context.Background(), img)
+}
+
+// PredictContext 执行旋转目标检测，在预处理、推理、后处理之间检查 ctx
+//
+// ctx 结束时返回 *vision.CanceledError，可通过 errors.Is(err, context.DeadlineExceeded) 判断
+func (e *OBBEngine) PredictContext(ctx context.Context, img image.Image) ([]OBBResult, error) {
+	results, err := e.PredictBatchContext(ctx, []image.Image{img})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// PredictBatch 批量执行旋转目标检测，返回的结果与 imgs 一一对应
+//
+// 模型输入的批大小为动态维度时，图片打包为 [N, 3, S, S] 的输入推理，每次最多 Config.MaxBatch 张 (0 表示一次推理所有图片)；
+// 批大小固定为 N 时每次推理 N 张，不足 N 张时补齐
+func (e *OBBEngine) PredictBatch(imgs []image.Image) ([][]OBBResult, error) {
+	return e.PredictBatchContext(context.Background(), imgs)
+}
+
+// PredictBatchContext 批量执行旋转目标检测，在预处理、推理、后处理之间检查 ctx
+func (e *OBBEngine) PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]OBBResult, error) {
+	return vision.PredictBatch(imgs, e.io.BatchSize, e.config.MaxBatch, func(batch []image.Image) ([][]OBBResult, error) {
+		return e.run(ctx, batch)
+	})
+}
+
+// run 将 imgs 打包为一个输入执行推理
+func (e *OBBEngine) run(ctx context.Context, imgs []image.Image) ([][]OBBResult, error) {
+	if err := vision.CheckContext(ctx, vision.StagePreprocess); err != nil {
+		return nil, err
+	}
+
 	// 预处理
-	inputTensor, params, err := preprocess(img, e.config.InputSize)
+	inputTensor, params, err := vision.PreprocessBatch(imgs, e.config.InputSize, e.config.Letterbox, e.config.PadValue)
 	if err != nil {
 		return nil, fmt.Errorf("预处理失败: %w", err)
 	}
 	defer inputTensor.Destroy()
 
 	// 推理
+	if err := vision.CheckContext(ctx, vision.StageInference); err != nil {
+		return nil, err
+	}
 	inputValues := map[string]*ort.Value{
-		"images": inputTensor,
+		e.io.Input: inputTensor,
 	}
 	outputValues, err := e.session.Run(inputValues)
 	if err != nil {
 		return nil, fmt.Errorf("推理失败: %w", err)
 	}
-	outputValue := outputValues["output0"]
+	outputValue := outputValues[e.io.Output]
 	defer outputValue.Destroy()
 
-	// 解析输出 [1, 300, 7]
+	if err := vision.CheckContext(ctx, vision.StagePostprocess); err != nil {
+		return nil, err
+	}
+
+	// Output Shape: [N, 300, 7]
 	data, err := ort.GetTensorData[float32](outputValue)
 	if err != nil {
 		return nil, fmt.Errorf("获取输出数据失败: %w", err)
 	}
-
 	shape, err := outputValue.GetShape()
-	if err != nil || len(shape) < 3 {
+	if err != nil {
+		return nil, fmt.Errorf("获取输出形状失败: %w", err)
+	}
+
+	// 后处理，按图片拆分输出
+	results := make([][]OBBResult, len(imgs))
+	for i := range imgs {
+		itemData, itemShape := vision.BatchItem(data, shape, i)
+		if results[i], err = e.postprocess(itemData, itemShape, params[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// postprocess 后处理
+func (e *OBBEngine) postprocess(data []float32, shape []int64, params vision.LetterboxParams) ([]OBBResult, error) {
+	if len(shape) < 3 {
 		return nil, fmt.Errorf("输出形状异常")
 	}
 
@@ -97,21 +173,22 @@ func (e *OBBEngine) Predict(img image.Image) ([]OBBResult, error) {
 		}
 
 		// 获取旋转矩形的4个角点
-		corners := getRotatedCorners(cx, cy, w, h, angle)
+		corners := vision.RotatedBox{CX: cx, CY: cy, W: w, H: h, Angle: angle}.Corners()
 
 		// 映射回原图坐标
 		var origCorners [4]image.Point
 		for j, pt := range corners {
 			// 边界检查
-			ox := min(max(0, int(math.Round(float64(pt[0]/params.scale)))), params.origW)
-			oy := min(max(0, int(math.Round(float64(pt[1]/params.scale)))), params.origH)
+			ox := min(max(0, int(math.Round(float64(params.OrigX(pt[0]))))), params.OrigW)
+			oy := min(max(0, int(math.Round(float64(params.OrigY(pt[1]))))), params.OrigH)
 			origCorners[j] = image.Point{X: ox, Y: oy}
 		}
 
 		results = append(results, OBBResult{
-			ClassID: classID,
-			Score:   score,
-			Corners: origCorners,
+			ClassID:   classID,
+			ClassName: vision.ClassName(e.config.Names, classID),
+			Score:     score,
+			Corners:   origCorners,
 			Center: image.Point{
 				X: (origCorners[0].X + origCorners[2].X) / 2,
 				Y: (origCorners[0].Y + origCorners[2].Y) / 2,
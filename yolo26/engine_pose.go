@@ -1,6 +1,7 @@
 package yolo26
 
 import (
+	"context"
 	"fmt"
 	"github.com/getcharzp/go-vision"
 	ort "github.com/getcharzp/onnxruntime_purego"
@@ -12,10 +13,21 @@ import (
 type PoseEngine struct {
 	session *ort.Session
 	config  Config
+	io      vision.ModelIO
 }
 
 // NewPoseEngine 初始化姿态引擎
 func NewPoseEngine(cfg Config) (*PoseEngine, error) {
+	// 读取模型信息，补全并校验模型参数
+	info, err := vision.ReadModelInfo(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	mio, err := applyModelInfo(&cfg, vision.TaskPose, info)
+	if err != nil {
+		return nil, err
+	}
+
 	oc := new(vision.OnnxConfig)
 	_ = convertutil.CopyProperties(cfg, oc)
 
@@ -33,6 +45,7 @@ func NewPoseEngine(cfg Config) (*PoseEngine, error) {
 	return &PoseEngine{
 		session: session,
 		config:  cfg,
+		io:      mio,
 	}, nil
 }
 
@@ -43,42 +56,98 @@ func (e *PoseEngine) Destroy() {
 	}
 }
 
+// Names 类别名称表，来自配置或模型元数据，未知时为 nil
+func (e *PoseEngine) Names() []string {
+	return e.config.Names
+}
+
 // Predict 执行姿态估计
 func (e *PoseEngine) Predict(img image.Image) ([]PoseResult, error) {
+	return e.PredictContext(context.Background(), img)
+}
+
+// PredictContext 执行姿态估计，在预处理、推理、后处理之间检查 ctx
+//
+// ctx 结束时返回 *vision.CanceledError，可通过 errors.Is(err, context.DeadlineExceeded) 判断
+func (e *PoseEngine) PredictContext(ctx context.Context, img image.Image) ([]PoseResult, error) {
+	results, err := e.PredictBatchContext(ctx, []image.Image{img})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// PredictBatch 批量执行姿态估计，返回的结果与 imgs 一一对应
+//
+// 模型输入的批大小为动态维度时，图片打包为 [N, 3, S, S] 的输入推理，每次最多 Config.MaxBatch 张 (0 表示一次推理所有图片)；
+// 批大小固定为 N 时每次推理 N 张，不足 N 张时补齐
+func (e *PoseEngine) PredictBatch(imgs []image.Image) ([][]PoseResult, error) {
+	return e.PredictBatchContext(context.Background(), imgs)
+}
+
+// PredictBatchContext 批量执行姿态估计，在预处理、推理、后处理之间检查 ctx
+func (e *PoseEngine) PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]PoseResult, error) {
+	return vision.PredictBatch(imgs, e.io.BatchSize, e.config.MaxBatch, func(batch []image.Image) ([][]PoseResult, error) {
+		return e.run(ctx, batch)
+	})
+}
+
+// run 将 imgs 打包为一个输入执行推理
+func (e *PoseEngine) run(ctx context.Context, imgs []image.Image) ([][]PoseResult, error) {
+	if err := vision.CheckContext(ctx, vision.StagePreprocess); err != nil {
+		return nil, err
+	}
+
 	// 预处理
-	inputTensor, params, err := preprocess(img, e.config.InputSize)
+	inputTensor, params, err := vision.PreprocessBatch(imgs, e.config.InputSize, e.config.Letterbox, e.config.PadValue)
 	if err != nil {
 		return nil, fmt.Errorf("预处理失败: %w", err)
 	}
 	defer inputTensor.Destroy()
 
 	// 推理
+	if err := vision.CheckContext(ctx, vision.StageInference); err != nil {
+		return nil, err
+	}
 	inputValues := map[string]*ort.Value{
-		"images": inputTensor,
+		e.io.Input: inputTensor,
 	}
 	outputValues, err := e.session.Run(inputValues)
 	if err != nil {
 		return nil, fmt.Errorf("推理失败: %w", err)
 	}
-	outputValue := outputValues["output0"]
+	outputValue := outputValues[e.io.Output]
 	defer outputValue.Destroy()
 
-	// Output Shaper: [1, 300, 57]
+	if err := vision.CheckContext(ctx, vision.StagePostprocess); err != nil {
+		return nil, err
+	}
+
+	// Output Shape: [N, 300, 57]
 	data, err := ort.GetTensorData[float32](outputValue)
 	if err != nil {
 		return nil, fmt.Errorf("获取输出数据失败: %w", err)
 	}
+	shape, err := outputValue.GetShape()
+	if err != nil {
+		return nil, fmt.Errorf("获取输出形状失败: %w", err)
+	}
 
-	// 后处理
-	return e.postprocess(data, params)
+	// 后处理，按图片拆分输出
+	results := make([][]PoseResult, len(imgs))
+	for i := range imgs {
+		itemData, itemShape := vision.BatchItem(data, shape, i)
+		if results[i], err = e.postprocess(itemData, itemShape, params[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
 }
 
 // postprocess 后处理
-func (e *PoseEngine) postprocess(data []float32, params imageParams) ([]PoseResult, error) {
-	const (
-		numObjects = 300
-		attributes = 57 // 4(box) + 1(score) + 1(class) + 51(kpts)
-	)
+func (e *PoseEngine) postprocess(data []float32, shape []int64, params vision.LetterboxParams) ([]PoseResult, error) {
+	numObjects := int(shape[1])
+	attributes := int(shape[2]) // 4(box) + 1(score) + 1(class) + nk*3(kpts)
 
 	results := make([]PoseResult, 0)
 
@@ -101,16 +170,17 @@ func (e *PoseEngine) postprocess(data []float32, params imageParams) ([]PoseResu
 		y2 := data[offset+3]
 
 		// 映射回原图尺寸
-		origX1 := int(x1 / params.scale)
-		origY1 := int(y1 / params.scale)
-		origX2 := int(x2 / params.scale)
-		origY2 := int(y2 / params.scale)
+		origX1 := int(params.OrigX(x1))
+		origY1 := int(params.OrigY(y1))
+		origX2 := int(params.OrigX(x2))
+		origY2 := int(params.OrigY(y2))
 
-		rawKpts := data[offset+6 : offset+57]
+		rawKpts := data[offset+6 : offset+attributes]
 		kpts := e.decodeKeyPoints(rawKpts, params)
 
 		results = append(results, PoseResult{
 			ClassID:   classID,
+			ClassName: vision.ClassName(e.config.Names, classID),
 			Score:     score,
 			Box:       image.Rect(origX1, origY1, origX2, origY2),
 			KeyPoints: kpts,
@@ -121,7 +191,7 @@ func (e *PoseEngine) postprocess(data []float32, params imageParams) ([]PoseResu
 }
 
 // decodeKeyPoints 关键点解码
-func (e *PoseEngine) decodeKeyPoints(raw []float32, params imageParams) []KeyPoint {
+func (e *PoseEngine) decodeKeyPoints(raw []float32, params vision.LetterboxParams) []KeyPoint {
 	kpts := make([]KeyPoint, e.config.NumKeyPoints)
 
 	for i := 0; i < e.config.NumKeyPoints; i++ {
@@ -131,8 +201,8 @@ func (e *PoseEngine) decodeKeyPoints(raw []float32, params imageParams) []KeyPoi
 		conf := raw[idx+2]
 
 		// 坐标映射回原图
-		origX := min(max(0, int(x/params.scale)), params.origW)
-		origY := min(max(0, int(y/params.scale)), params.origH)
+		origX := min(max(0, int(params.OrigX(x))), params.OrigW)
+		origY := min(max(0, int(params.OrigY(y))), params.OrigH)
 
 		kpts[i] = KeyPoint{
 			X:     origX,
@@ -1,22 +1,33 @@
 package yolo26
 
 import (
+	"context"
 	"fmt"
 	"github.com/getcharzp/go-vision"
 	ort "github.com/getcharzp/onnxruntime_purego"
 	"github.com/up-zero/gotool/convertutil"
 	"image"
-	"image/color"
 )
 
 // SegEngine YOLO26-seg Engine
 type SegEngine struct {
 	session *ort.Session
 	config  Config
+	io      vision.ModelIO
 }
 
 // NewSegEngine 初始化分割引擎
 func NewSegEngine(cfg Config) (*SegEngine, error) {
+	// 读取模型信息，补全并校验模型参数
+	info, err := vision.ReadModelInfo(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	mio, err := applyModelInfo(&cfg, vision.TaskSegment, info)
+	if err != nil {
+		return nil, err
+	}
+
 	oc := new(vision.OnnxConfig)
 	_ = convertutil.CopyProperties(cfg, oc)
 	// 初始化 ONNX
@@ -33,6 +44,7 @@ func NewSegEngine(cfg Config) (*SegEngine, error) {
 	return &SegEngine{
 		session: session,
 		config:  cfg,
+		io:      mio,
 	}, nil
 }
 
@@ -43,18 +55,66 @@ func (e *SegEngine) Destroy() {
 	}
 }
 
+// Names 类别名称表，来自配置或模型元数据，未知时为 nil
+func (e *SegEngine) Names() []string {
+	return e.config.Names
+}
+
+// MaskResolution 结果中 Mask 的分辨率
+func (e *SegEngine) MaskResolution() vision.MaskResolution {
+	return e.config.MaskResolution
+}
+
 // Predict 执行分割推理
 func (e *SegEngine) Predict(img image.Image) ([]SegResult, error) {
+	return e.PredictContext(context.Background(), img)
+}
+
+// PredictContext 执行分割推理，在预处理、推理、后处理之间检查 ctx
+//
+// ctx 结束时返回 *vision.CanceledError，可通过 errors.Is(err, context.DeadlineExceeded) 判断
+func (e *SegEngine) PredictContext(ctx context.Context, img image.Image) ([]SegResult, error) {
+	results, err := e.PredictBatchContext(ctx, []image.Image{img})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// PredictBatch 批量执行分割推理，返回的结果与 imgs 一一对应
+//
+// 模型输入的批大小为动态维度时，图片打包为 [N, 3, S, S] 的输入推理，每次最多 Config.MaxBatch 张 (0 表示一次推理所有图片)；
+// 批大小固定为 N 时每次推理 N 张，不足 N 张时补齐
+func (e *SegEngine) PredictBatch(imgs []image.Image) ([][]SegResult, error) {
+	return e.PredictBatchContext(context.Background(), imgs)
+}
+
+// PredictBatchContext 批量执行分割推理，在预处理、推理、后处理之间检查 ctx
+func (e *SegEngine) PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]SegResult, error) {
+	return vision.PredictBatch(imgs, e.io.BatchSize, e.config.MaxBatch, func(batch []image.Image) ([][]SegResult, error) {
+		return e.run(ctx, batch)
+	})
+}
+
+// run 将 imgs 打包为一个输入执行推理
+func (e *SegEngine) run(ctx context.Context, imgs []image.Image) ([][]SegResult, error) {
+	if err := vision.CheckContext(ctx, vision.StagePreprocess); err != nil {
+		return nil, err
+	}
+
 	// 预处理
-	inputTensor, params, err := preprocess(img, e.config.InputSize)
+	inputTensor, params, err := vision.PreprocessBatch(imgs, e.config.InputSize, e.config.Letterbox, e.config.PadValue)
 	if err != nil {
 		return nil, fmt.Errorf("预处理失败: %w", err)
 	}
 	defer inputTensor.Destroy()
 
 	// 推理
+	if err := vision.CheckContext(ctx, vision.StageInference); err != nil {
+		return nil, err
+	}
 	inputValues := map[string]*ort.Value{
-		"images": inputTensor,
+		e.io.Input: inputTensor,
 	}
 	outputValues, err := e.session.Run(inputValues)
 	if err != nil {
@@ -66,31 +126,56 @@ func (e *SegEngine) Predict(img image.Image) ([]SegResult, error) {
 		}
 	}()
 
-	// output0: Detections [1,300,38]
-	// output1: Mask Protos [1, 32, 160, 160]
-
-	// 后处理
-	return e.postprocess(outputValues["output0"], outputValues["output1"], params)
-}
+	if err := vision.CheckContext(ctx, vision.StagePostprocess); err != nil {
+		return nil, err
+	}
 
-// postprocess 后处理
-func (e *SegEngine) postprocess(out0, out1 *ort.Value, params imageParams) ([]SegResult, error) {
+	// output0: Detections [N, 300, 38]
+	// output1: Mask Protos [N, 32, 160, 160]
+	out0, out1 := outputValues[e.io.Output], outputValues[e.io.Protos]
 	data0, err := ort.GetTensorData[float32](out0)
 	if err != nil {
-		return nil, fmt.Errorf("获取数据失败: %w", err)
+		return nil, fmt.Errorf("获取输出数据失败: %w", err)
+	}
+	shape0, err := out0.GetShape()
+	if err != nil {
+		return nil, fmt.Errorf("获取输出形状失败: %w", err)
 	}
 	data1, err := ort.GetTensorData[float32](out1)
 	if err != nil {
 		return nil, fmt.Errorf("获取 Mask Protos 失败: %w", err)
 	}
-	shape1, _ := out1.GetShape()
-	protoC, protoH, protoW := int(shape1[1]), int(shape1[2]), int(shape1[3])
+	shape1, err := out1.GetShape()
+	if err != nil {
+		return nil, fmt.Errorf("获取输出形状失败: %w", err)
+	}
+
+	// 后处理，按图片拆分输出
+	results := make([][]SegResult, len(imgs))
+	for i := range imgs {
+		dets, detsShape := vision.BatchItem(data0, shape0, i)
+		protos, protosShape := vision.BatchItem(data1, shape1, i)
+		if results[i], err = e.postprocess(ctx, dets, detsShape, protos, protosShape, params[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
 
-	const (
-		numDetections = 300
-		dimTotal      = 38
-		indexMask     = 6
-	)
+// postprocess 后处理
+//
+// # Params:
+//
+//	data0, shape0: 单张图片的检测输出，形状为 [1, 300, 38]
+//	protoData, shape1: 单张图片的 Mask 原型，形状为 [1, 32, 160, 160]
+//	params: 图片尺寸信息
+func (e *SegEngine) postprocess(ctx context.Context, data0 []float32, shape0 []int64, protoData []float32, shape1 []int64, params vision.LetterboxParams) ([]SegResult, error) {
+	protos := vision.MaskProtos{Data: protoData, C: int(shape1[1]), H: int(shape1[2]), W: int(shape1[3])}
+
+	const indexMask = 6
+	numDetections := int(shape0[1])
+	dimTotal := int(shape0[2]) // 4(box) + 1(score) + 1(class) + nm(mask)
+	numCoeffs := dimTotal - indexMask
 
 	var results []SegResult
 
@@ -110,23 +195,30 @@ func (e *SegEngine) postprocess(out0, out1 *ort.Value, params imageParams) ([]Se
 		classID := int(data0[offset+5])
 
 		// 映射回原图
-		origX1 := max(0, int(x1/params.scale))
-		origY1 := max(0, int(y1/params.scale))
-		origX2 := min(params.origW, int(x2/params.scale))
-		origY2 := min(params.origH, int(y2/params.scale))
+		origX1 := max(0, int(params.OrigX(x1)))
+		origY1 := max(0, int(params.OrigY(y1)))
+		origX2 := min(params.OrigW, int(params.OrigX(x2)))
+		origY2 := min(params.OrigH, int(params.OrigY(y2)))
 		origBox := image.Rect(origX1, origY1, origX2, origY2)
 
-		// 提取 32 位 Mask 系数
-		coeffs := data0[offset+indexMask : offset+indexMask+32]
+		// 提取 Mask 系数
+		coeffs := data0[offset+indexMask : offset+indexMask+numCoeffs]
+
+		// 逐个实例解码 Mask 耗时较长，每个实例之前检查 ctx
+		if err := vision.CheckContext(ctx, vision.StagePostprocess); err != nil {
+			return nil, err
+		}
 
 		// 解码 Mask
-		mask := e.decodeMask(origBox, coeffs, data1, protoC, protoH, protoW, params)
+		mask, maskSize := e.decodeMask(origBox, coeffs, protos, params)
 
 		results = append(results, SegResult{
-			ClassID: classID,
-			Score:   score,
-			Box:     origBox,
-			Mask:    mask,
+			ClassID:   classID,
+			ClassName: vision.ClassName(e.config.Names, classID),
+			Score:     score,
+			Box:       origBox,
+			Mask:      mask,
+			MaskSize:  maskSize,
 		})
 	}
 
@@ -135,41 +227,22 @@ func (e *SegEngine) postprocess(out0, out1 *ort.Value, params imageParams) ([]Se
 
 // decodeMask Mask解码
 //
+// 在原型分辨率上计算一次 Mask，双线性插值到 MaskResolution 指定的分辨率后在检测框内二值化，
+// 返回只保存检测框区域的 Mask，以及 Mask 坐标系下整幅图片的尺寸
+//
 // # Params:
 //
 //	origBox: 原图上的检测框
-//	maskCoeffs: 当前检测框对应的 32 个 Mask 系数
-//	protos: 模型 Output1 的原型数据
-//	c, h, w: 原型数据的维度 (32, 160, 160)
+//	maskCoeffs: 当前检测框对应的 Mask 系数
+//	protos: 模型输出的 Mask 原型
 //	params: 图片尺寸缩放参数
-func (e *SegEngine) decodeMask(origBox image.Rectangle, maskCoeffs []float32, protos []float32, c, h, w int, params imageParams) *image.Gray {
-	finalMask := image.NewGray(image.Rect(0, 0, params.origW, params.origH))
-
-	// Mask 原型图相对于 InputSize(640) 的缩放比例
-	maskStride := float32(e.config.InputSize) / float32(w)
-
-	for y := origBox.Min.Y; y < origBox.Max.Y; y++ {
-		for x := origBox.Min.X; x < origBox.Max.X; x++ {
-			// 映射回 640 尺度
-			inputX := float32(x) * params.scale
-			inputY := float32(y) * params.scale
-
-			// 映射回 160 Mask 尺度
-			mx := int(inputX / maskStride)
-			my := int(inputY / maskStride)
-
-			if mx >= 0 && mx < w && my >= 0 && my < h {
-				// 计算该像素的 Mask 值 (Dot Product)
-				sum := float32(0.0)
-				for k := 0; k < c; k++ {
-					sum += maskCoeffs[k] * protos[k*h*w+my*w+mx]
-				}
-
-				if sigmoid(sum) > e.config.MaskThreshold {
-					finalMask.SetGray(x, y, color.Gray{Y: 255})
-				}
-			}
-		}
-	}
-	return finalMask
+func (e *SegEngine) decodeMask(origBox image.Rectangle, maskCoeffs []float32, protos vision.MaskProtos, params vision.LetterboxParams) (*image.Gray, image.Point) {
+	// Mask 原型相对于 InputSize 的步长，例如 640/160 = 4
+	stride := float32(e.config.InputSize) / float32(protos.W)
+	size, box, t := params.MaskLayout(e.config.MaskResolution, origBox, stride)
+
+	// 只保存检测框区域的 Mask
+	mask := image.NewGray(box.Intersect(image.Rect(0, 0, size.X, size.Y)))
+	protos.Decode(mask, maskCoeffs, box, t, e.config.MaskThreshold)
+	return mask, size
 }
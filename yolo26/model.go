@@ -0,0 +1,146 @@
+package yolo26
+
+import (
+	"fmt"
+	"github.com/getcharzp/go-vision"
+)
+
+// applyModelInfo 根据模型信息补全并校验配置中的模型参数
+//
+// YOLO26 为端到端模型，检测类输出形状为 [N, K, 6+extra]，每行为 x1, y1, x2, y2 (或 cx, cy, w, h), score, class, ...
+//
+// # Params:
+//
+//	cfg: 引擎配置，InputSize、NumClasses、NumMaskCoeffs、NumKeyPoints 为 0 时从模型中读取
+//	task: 引擎对应的任务类型，例如 vision.TaskDetect
+//	info: vision.ReadModelInfo 读取的模型信息
+func applyModelInfo(cfg *Config, task string, info *vision.ModelInfo) (vision.ModelIO, error) {
+	mio, err := info.ResolveInput(task, &cfg.InputSize)
+	if err != nil {
+		return mio, err
+	}
+
+	// 类别名称优先使用配置，其次使用模型元数据
+	names := info.ClassNames(cfg.Names)
+	numNames := len(names)
+
+	switch task {
+	case vision.TaskClassify:
+		// 输出 [N, nc]
+		if len(info.Outputs) != 1 || len(info.Outputs[0].Shape) != 2 {
+			return mio, fmt.Errorf("%w: 分类模型应有 1 个形状为 [N, nc] 的输出", vision.ErrModelMismatch)
+		}
+		out := info.Outputs[0]
+		mio.Output = out.Name
+		if err := vision.ResolveParam("NumClasses", &cfg.NumClasses, int(out.Dim(1)), numNames); err != nil {
+			return mio, err
+		}
+
+	case vision.TaskSegment:
+		// output0 [N, K, 6+nm], output1 [N, nm, h, w]
+		if len(info.Outputs) != 2 {
+			return mio, fmt.Errorf("%w: 分割模型应有 2 个输出，实际 %d 个", vision.ErrModelMismatch, len(info.Outputs))
+		}
+		det, protos := info.Outputs[0], info.Outputs[1]
+		if len(det.Shape) == 4 {
+			det, protos = protos, det
+		}
+		if len(det.Shape) != 3 || len(protos.Shape) != 4 {
+			return mio, fmt.Errorf("%w: 分割模型输出形状应为 [N, K, 6+nm] 和 [N, nm, H, W]，实际 %v 和 %v",
+				vision.ErrModelMismatch, det.Shape, protos.Shape)
+		}
+		mio.Output, mio.Protos = det.Name, protos.Name
+
+		numCoeffs := -1
+		if a := det.Dim(2); a > 0 {
+			numCoeffs = int(a) - 6
+		}
+		if err := vision.ResolveParam("NumMaskCoeffs", &cfg.NumMaskCoeffs, int(protos.Dim(1)), numCoeffs); err != nil {
+			return mio, err
+		}
+		if cfg.NumMaskCoeffs <= 0 {
+			return mio, fmt.Errorf("%w: 无法确定 Mask 系数数量", vision.ErrModelMismatch)
+		}
+		if err := vision.ResolveParam("NumClasses", &cfg.NumClasses, numNames); err != nil {
+			return mio, err
+		}
+
+	case vision.TaskPose:
+		// output0 [N, K, 6+nk*3]
+		out, err := info.SingleOutput("姿态", 3)
+		if err != nil {
+			return mio, err
+		}
+		mio.Output = out.Name
+
+		numKeyPoints := 0
+		if k, d, ok := info.KeyPointShape(); ok {
+			if d != 3 {
+				return mio, fmt.Errorf("%w: 暂不支持每个关键点 %d 个数值的模型 (需要 x, y, conf)", vision.ErrModelMismatch, d)
+			}
+			numKeyPoints = k
+		}
+		if a := out.Dim(2); a > 0 {
+			if (a-6)%3 != 0 {
+				return mio, fmt.Errorf("%w: 姿态模型输出维度 %d 不是 6+nk*3", vision.ErrModelMismatch, a)
+			}
+			if err := vision.ResolveParam("NumKeyPoints", &cfg.NumKeyPoints, int(a-6)/3, numKeyPoints); err != nil {
+				return mio, err
+			}
+		} else if err := vision.ResolveParam("NumKeyPoints", &cfg.NumKeyPoints, numKeyPoints); err != nil {
+			return mio, err
+		}
+		if cfg.NumKeyPoints <= 0 {
+			cfg.NumKeyPoints = 17
+		}
+		if err := vision.ResolveParam("NumClasses", &cfg.NumClasses, numNames); err != nil {
+			return mio, err
+		}
+		// 未指定时默认为单类别 (person)
+		if cfg.NumClasses == 0 {
+			cfg.NumClasses = 1
+		}
+
+	case vision.TaskOBB:
+		// output0 [N, K, 7]
+		out, err := info.SingleOutput("旋转目标检测", 3)
+		if err != nil {
+			return mio, err
+		}
+		if err := checkAttrs(out, 7); err != nil {
+			return mio, err
+		}
+		mio.Output = out.Name
+		if err := vision.ResolveParam("NumClasses", &cfg.NumClasses, numNames); err != nil {
+			return mio, err
+		}
+
+	default:
+		// output0 [N, K, 6]
+		out, err := info.SingleOutput("检测", 3)
+		if err != nil {
+			return mio, err
+		}
+		if err := checkAttrs(out, 6); err != nil {
+			return mio, err
+		}
+		mio.Output = out.Name
+		if err := vision.ResolveParam("NumClasses", &cfg.NumClasses, numNames); err != nil {
+			return mio, err
+		}
+	}
+
+	cfg.Names = vision.ResolveNames(task, cfg.NumClasses, names)
+	if cfg.NumClasses == 0 {
+		cfg.NumClasses = len(cfg.Names)
+	}
+	return mio, nil
+}
+
+// checkAttrs 校验每个检测结果的属性数量
+func checkAttrs(out vision.TensorInfo, expected int) error {
+	if a := out.Dim(2); a > 0 && int(a) != expected {
+		return fmt.Errorf("%w: 输出 %s 的属性数为 %d，预期 %d", vision.ErrModelMismatch, out.Name, a, expected)
+	}
+	return nil
+}
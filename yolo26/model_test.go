@@ -0,0 +1,54 @@
+package yolo26
+
+import (
+	"errors"
+	"github.com/getcharzp/go-vision"
+	"testing"
+)
+
+func TestApplyModelInfo(t *testing.T) {
+	newInfo := func(metadata map[string]string, outputs ...vision.TensorInfo) *vision.ModelInfo {
+		return &vision.ModelInfo{
+			Inputs:   []vision.TensorInfo{{Name: "images", Shape: []int64{1, 3, 640, 640}}},
+			Outputs:  outputs,
+			Metadata: metadata,
+		}
+	}
+
+	seg := newInfo(map[string]string{"names": "{0: 'a', 1: 'b'}"},
+		vision.TensorInfo{Name: "output0", Shape: []int64{1, 300, 38}},
+		vision.TensorInfo{Name: "output1", Shape: []int64{1, 32, 160, 160}})
+	cfg := DefaultSegConfig()
+	mio, err := applyModelInfo(&cfg, vision.TaskSegment, seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.NumClasses != 2 || cfg.NumMaskCoeffs != 32 || mio.Protos != "output1" {
+		t.Fatalf("分割模型参数错误: %+v %+v", cfg, mio)
+	}
+
+	pose := newInfo(map[string]string{}, vision.TensorInfo{Name: "output0", Shape: []int64{1, 300, 57}})
+	cfg = DefaultPoseConfig()
+	if _, err := applyModelInfo(&cfg, vision.TaskPose, pose); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.NumKeyPoints != 17 || cfg.NumClasses != 1 {
+		t.Fatalf("应读取到 NumKeyPoints=17 NumClasses=1，实际 %d %d", cfg.NumKeyPoints, cfg.NumClasses)
+	}
+
+	// 元数据中没有类别名称时使用内置类别表
+	cfg = DefaultDetConfig()
+	det := newInfo(map[string]string{}, vision.TensorInfo{Name: "output0", Shape: []int64{1, 300, 6}})
+	if _, err := applyModelInfo(&cfg, vision.TaskDetect, det); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.NumClasses != 80 || vision.ClassName(cfg.Names, 2) != "car" {
+		t.Fatalf("应使用 COCO 类别表，实际 %d %q", cfg.NumClasses, cfg.Names)
+	}
+
+	// 检测模型不能用于 OBB 引擎
+	cfg = DefaultOBBConfig()
+	if _, err := applyModelInfo(&cfg, vision.TaskOBB, det); !errors.Is(err, vision.ErrModelMismatch) {
+		t.Fatalf("检测模型用于 OBB 引擎时应返回 ErrModelMismatch，实际 %v", err)
+	}
+}
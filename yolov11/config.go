@@ -14,12 +14,25 @@ type Config struct {
 	ConfThreshold float32 // 置信度阈值 (默认 0.45)
 	IOUThreshold  float32 // NMS IOU 阈值 (默认 0.5)
 	MaskThreshold float32 // Mask 二值化阈值 (默认 0.5)
+	// Mask 的分辨率 (默认 vision.MaskOriginal，与原图尺寸一致)，MaskInput、MaskProto 可降低内存占用
+	MaskResolution vision.MaskResolution
+	AgnosticNMS    bool // 是否跨类别执行 NMS (默认 false，按类别分别抑制)
 
-	// 模型参数
-	InputSize     int // 默认 640
-	NumClasses    int // 默认 80
-	NumMaskCoeffs int // 默认 32
-	NumKeyPoints  int // 默认 17
+	// 模型参数，为 0 时从模型的输入输出形状及元数据中读取，非 0 时必须与模型一致
+	InputSize     int // 输入尺寸，例如 640
+	NumClasses    int // 类别数量，例如 80
+	NumMaskCoeffs int // Mask 系数数量，例如 32
+	NumKeyPoints  int // 关键点数量，例如 17
+
+	// 类别名称，为空时从模型元数据 (names) 中读取，元数据中不存在时使用内置的 COCO、DOTA、ImageNet 类别表
+	Names []string
+
+	// 预处理参数
+	Letterbox vision.LetterboxMode // 图片在模型输入中的放置方式 (默认居中)，分类模型使用短边缩放 + 居中裁剪，不使用该参数
+	PadValue  uint8                // 填充区域的灰度值 (默认 114)，分类模型不使用该参数
+
+	// 批量推理参数
+	MaxBatch int // PredictBatch 每次推理的最大图片数量 (默认 0 不限制)，仅对批大小为动态维度的模型生效
 
 	// 可选参数
 	UseCuda           bool // (可选) 是否启用 CUDA
@@ -34,10 +47,8 @@ func DefaultConfig() Config {
 		ConfThreshold:      0.45,
 		IOUThreshold:       0.50,
 		MaskThreshold:      0.50,
-		InputSize:          640,
-		NumClasses:         80,
-		NumMaskCoeffs:      32,
-		NumKeyPoints:       17,
+		Letterbox:          vision.LetterboxCenter,
+		PadValue:           114,
 	}
 }
 
@@ -58,7 +69,6 @@ func DefaultSegConfig() Config {
 // DefaultClsConfig 分类的默认配置
 func DefaultClsConfig() Config {
 	cfg := DefaultConfig()
-	cfg.InputSize = 224
 	cfg.ModelPath = "./yolov11_weights/yolo11m-cls.onnx"
 	return cfg
 }
@@ -66,7 +76,6 @@ func DefaultClsConfig() Config {
 // DefaultPoseConfig 姿势的默认配置
 func DefaultPoseConfig() Config {
 	cfg := DefaultConfig()
-	cfg.NumClasses = 1
 	cfg.ModelPath = "./yolov11_weights/yolo11m-pose.onnx"
 	return cfg
 }
@@ -74,18 +83,10 @@ func DefaultPoseConfig() Config {
 // DefaultOBBConfig OBB的默认配置
 func DefaultOBBConfig() Config {
 	cfg := DefaultConfig()
-	cfg.InputSize = 1024
-	cfg.NumClasses = 15
 	cfg.ModelPath = "./yolov11_weights/yolo11m-obb.onnx"
 	return cfg
 }
 
-// imageParams 图片尺寸信息
-type imageParams struct {
-	origW, origH int
-	scale        float32
-}
-
 // 候选结果
 type candidate struct {
 	box          [4]float32      // 模型输出的 cx, cy, w, h
@@ -97,65 +98,34 @@ type candidate struct {
 	angle        float32 // 旋转角度
 }
 
-// DetResult 目标检测结果
-type DetResult struct {
-	// 分类ID，例如：
-	//	0: person
-	//  1: bicycle
-	//  2: car
-	// - 详细映射参考：
-	//	https://github.com/ultralytics/ultralytics/blob/main/ultralytics/cfg/datasets/coco.yaml
-	ClassID int
-	Score   float32
-	Box     image.Rectangle // 检测框
-}
-
-// SegResult 分割结果
-type SegResult struct {
-	// 分类ID，例如：
-	//	0: person
-	//  1: bicycle
-	//  2: car
-	// 详细映射参考：
-	//	https://github.com/ultralytics/ultralytics/blob/main/ultralytics/cfg/datasets/coco.yaml
-	ClassID int
-	Score   float32
-	Box     image.Rectangle // 分割出的矩形区域
-	Mask    *image.Gray     // 解码后的 Mask
-}
-
-// ClassResult 分类结果
-type ClassResult struct {
-	// 分类ID，例如：
-	//	436: station wagon
-	//	656: minivan
-	// 详细映射参考：
-	//	https://github.com/ultralytics/ultralytics/blob/main/ultralytics/cfg/datasets/ImageNet.yaml
-	ClassID int
-	Score   float32
-}
-
-// KeyPoint 单个关键点
-type KeyPoint struct {
-	X, Y  int     // 原图坐标
-	Score float32 // 可见性/置信度
-}
-
-// PoseResult 姿态估计结果
-type PoseResult struct {
-	ClassID   int
-	Score     float32
-	Box       image.Rectangle
-	KeyPoints []KeyPoint // 关键点列表
+// rotatedBox 模型输入尺度下的旋转矩形
+func (c *candidate) rotatedBox() vision.RotatedBox {
+	return vision.RotatedBox{CX: c.box[0], CY: c.box[1], W: c.box[2], H: c.box[3], Angle: c.angle}
 }
 
-// OBBResult 旋转目标检测结果
-type OBBResult struct {
-	ClassID int
-	Score   float32
-	// 旋转框的顶点坐标：TopLeft, TopRight, BottomRight, BottomLeft
-	Corners [4]image.Point
+// 结果类型定义在 vision 包中，与 yolo26 共用
+type (
+	DetResult   = vision.DetResult
+	SegResult   = vision.SegResult
+	ClassResult = vision.ClassResult
+	KeyPoint    = vision.KeyPoint
+	PoseResult  = vision.PoseResult
+	OBBResult   = vision.OBBResult
+)
 
-	Center image.Point
-	Angle  float32 // 弧度
-}
+// 各引擎实现的通用接口
+var (
+	_ vision.Detector      = (*DetEngine)(nil)
+	_ vision.Segmenter     = (*SegEngine)(nil)
+	_ vision.PoseEstimator = (*PoseEngine)(nil)
+	_ vision.OBBDetector   = (*OBBEngine)(nil)
+	_ vision.Classifier    = (*ClsEngine)(nil)
+
+	_ vision.Namer = (*DetEngine)(nil)
+	_ vision.Namer = (*SegEngine)(nil)
+	_ vision.Namer = (*PoseEngine)(nil)
+	_ vision.Namer = (*OBBEngine)(nil)
+	_ vision.Namer = (*ClsEngine)(nil)
+
+	_ vision.MaskResolver = (*SegEngine)(nil)
+)
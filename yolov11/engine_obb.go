@@ -1,6 +1,7 @@
 package yolov11
 
 import (
+	"context"
 	"fmt"
 	"github.com/getcharzp/go-vision"
 	ort "github.com/getcharzp/onnxruntime_purego"
@@ -13,10 +14,21 @@ import (
 type OBBEngine struct {
 	session *ort.Session
 	config  Config
+	io      vision.ModelIO
 }
 
 // NewOBBEngine 初始化 OBB 引擎
 func NewOBBEngine(cfg Config) (*OBBEngine, error) {
+	// 读取模型信息，补全并校验模型参数
+	info, err := vision.ReadModelInfo(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	mio, err := applyModelInfo(&cfg, vision.TaskOBB, info)
+	if err != nil {
+		return nil, err
+	}
+
 	oc := new(vision.OnnxConfig)
 	if err := convertutil.CopyProperties(cfg, oc); err != nil {
 		return nil, fmt.Errorf("复制参数失败: %w", err)
@@ -35,6 +47,7 @@ func NewOBBEngine(cfg Config) (*OBBEngine, error) {
 	return &OBBEngine{
 		session: session,
 		config:  cfg,
+		io:      mio,
 	}, nil
 }
 
@@ -45,27 +58,74 @@ func (e *OBBEngine) Destroy() {
 	}
 }
 
+// Names 类别名称表，来自配置或模型元数据，未知时为 nil
+func (e *OBBEngine) Names() []string {
+	return e.config.Names
+}
+
 // Predict 执行旋转目标检测
 func (e *OBBEngine) Predict(img image.Image) ([]OBBResult, error) {
+	return e.PredictContext(context.Background(), img)
+}
+
+// PredictContext 执行旋转目标检测，在预处理、推理、后处理之间检查 ctx
+//
+// ctx 结束时返回 *vision.CanceledError，可通过 errors.Is(err, context.DeadlineExceeded) 判断
+func (e *OBBEngine) PredictContext(ctx context.Context, img image.Image) ([]OBBResult, error) {
+	results, err := e.PredictBatchContext(ctx, []image.Image{img})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// PredictBatch 批量执行旋转目标检测，返回的结果与 imgs 一一对应
+//
+// 模型输入的批大小为动态维度时，图片打包为 [N, 3, S, S] 的输入推理，每次最多 Config.MaxBatch 张 (0 表示一次推理所有图片)；
+// 批大小固定为 N 时每次推理 N 张，不足 N 张时补齐
+func (e *OBBEngine) PredictBatch(imgs []image.Image) ([][]OBBResult, error) {
+	return e.PredictBatchContext(context.Background(), imgs)
+}
+
+// PredictBatchContext 批量执行旋转目标检测，在预处理、推理、后处理之间检查 ctx
+func (e *OBBEngine) PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]OBBResult, error) {
+	return vision.PredictBatch(imgs, e.io.BatchSize, e.config.MaxBatch, func(batch []image.Image) ([][]OBBResult, error) {
+		return e.run(ctx, batch)
+	})
+}
+
+// run 将 imgs 打包为一个输入执行推理
+func (e *OBBEngine) run(ctx context.Context, imgs []image.Image) ([][]OBBResult, error) {
+	if err := vision.CheckContext(ctx, vision.StagePreprocess); err != nil {
+		return nil, err
+	}
+
 	// 预处理
-	inputTensor, params, err := preprocess(img, e.config.InputSize)
+	inputTensor, params, err := vision.PreprocessBatch(imgs, e.config.InputSize, e.config.Letterbox, e.config.PadValue)
 	if err != nil {
 		return nil, fmt.Errorf("预处理失败: %w", err)
 	}
 	defer inputTensor.Destroy()
 
 	// 推理
+	if err := vision.CheckContext(ctx, vision.StageInference); err != nil {
+		return nil, err
+	}
 	inputValues := map[string]*ort.Value{
-		"images": inputTensor,
+		e.io.Input: inputTensor,
 	}
 	outputValues, err := e.session.Run(inputValues)
 	if err != nil {
 		return nil, fmt.Errorf("推理失败: %w", err)
 	}
-	outputValue := outputValues["output0"]
+	outputValue := outputValues[e.io.Output]
 	defer outputValue.Destroy()
 
-	// Output Shaper: [1, 20, 21504]
+	if err := vision.CheckContext(ctx, vision.StagePostprocess); err != nil {
+		return nil, err
+	}
+
+	// Output Shape: [N, 20, 21504]
 	data, err := ort.GetTensorData[float32](outputValue)
 	if err != nil {
 		return nil, fmt.Errorf("获取输出数据失败: %w", err)
@@ -75,42 +135,50 @@ func (e *OBBEngine) Predict(img image.Image) ([]OBBResult, error) {
 		return nil, fmt.Errorf("获取输出形状失败: %w", err)
 	}
 
-	// 后处理
-	return e.postprocess(data, shape, params)
+	// 后处理，按图片拆分输出
+	results := make([][]OBBResult, len(imgs))
+	for i := range imgs {
+		itemData, itemShape := vision.BatchItem(data, shape, i)
+		if results[i], err = e.postprocess(itemData, itemShape, params[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
 }
 
 // postprocess 后处理
-func (e *OBBEngine) postprocess(data []float32, shape []int64, params imageParams) ([]OBBResult, error) {
+func (e *OBBEngine) postprocess(data []float32, shape []int64, params vision.LetterboxParams) ([]OBBResult, error) {
 	numChannels := int(shape[1])
 	numAnchors := int(shape[2])
 
 	// 解析候选框
 	candidates := e.parseCandidates(data, numChannels, numAnchors, params)
-	// NMS
-	keptIndices := nms(candidates, e.config.IOUThreshold)
+	// 旋转框 NMS
+	keptIndices := nmsRotated(candidates, e.config.IOUThreshold, e.config.AgnosticNMS)
 
 	results := make([]OBBResult, 0, len(keptIndices))
 	for _, idx := range keptIndices {
 		cand := candidates[idx]
 
 		// 重新计算旋转后的 4 个角点
-		corners := getRotatedCorners(cand.box[0], cand.box[1], cand.box[2], cand.box[3], cand.angle)
+		corners := cand.rotatedBox().Corners()
 
 		// 映射回原图坐标
 		origCorners := [4]image.Point{}
 		for i, pt := range corners {
-			ox := min(max(0, int(pt[0]/params.scale)), params.origW)
-			oy := min(max(0, int(pt[1]/params.scale)), params.origH)
+			ox := min(max(0, int(params.OrigX(pt[0]))), params.OrigW)
+			oy := min(max(0, int(params.OrigY(pt[1]))), params.OrigH)
 
 			origCorners[i] = image.Point{X: ox, Y: oy}
 		}
 
 		results = append(results, OBBResult{
-			ClassID: cand.classID,
-			Score:   cand.score,
-			Corners: origCorners,
-			Center:  image.Point{X: (origCorners[0].X + origCorners[2].X) / 2, Y: (origCorners[0].Y + origCorners[2].Y) / 2},
-			Angle:   cand.angle,
+			ClassID:   cand.classID,
+			ClassName: vision.ClassName(e.config.Names, cand.classID),
+			Score:     cand.score,
+			Corners:   origCorners,
+			Center:    image.Point{X: (origCorners[0].X + origCorners[2].X) / 2, Y: (origCorners[0].Y + origCorners[2].Y) / 2},
+			Angle:     cand.angle,
 		})
 	}
 
@@ -118,7 +186,7 @@ func (e *OBBEngine) postprocess(data []float32, shape []int64, params imageParam
 }
 
 // parseCandidates 解析候选框
-func (e *OBBEngine) parseCandidates(data []float32, channels, anchors int, params imageParams) []candidate {
+func (e *OBBEngine) parseCandidates(data []float32, channels, anchors int, params vision.LetterboxParams) []candidate {
 	// data
 	// T [cx, cy, w, h, c1...c15, angle]
 
@@ -148,7 +216,7 @@ func (e *OBBEngine) parseCandidates(data []float32, channels, anchors int, param
 		angle := data[angleIdx*anchors+i]
 
 		// 获取旋转矩形的4个角点
-		corners := getRotatedCorners(cx, cy, w, h, angle)
+		corners := vision.RotatedBox{CX: cx, CY: cy, W: w, H: h, Angle: angle}.Corners()
 
 		// 找外接矩形的 min/max
 		minX, minY := float32(math.MaxFloat32), float32(math.MaxFloat32)
@@ -159,10 +227,10 @@ func (e *OBBEngine) parseCandidates(data []float32, channels, anchors int, param
 			minY = min(pt[1], minY)
 			maxY = max(pt[1], maxY)
 		}
-		origX1 := int(minX / params.scale)
-		origY1 := int(minY / params.scale)
-		origX2 := int(maxX / params.scale)
-		origY2 := int(maxY / params.scale)
+		origX1 := int(params.OrigX(minX))
+		origY1 := int(params.OrigY(minY))
+		origX2 := int(params.OrigX(maxX))
+		origY2 := int(params.OrigY(maxY))
 
 		cands = append(cands, candidate{
 			box:     [4]float32{cx, cy, w, h},
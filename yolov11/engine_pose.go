@@ -1,6 +1,7 @@
 package yolov11
 
 import (
+	"context"
 	"fmt"
 	"github.com/getcharzp/go-vision"
 	ort "github.com/getcharzp/onnxruntime_purego"
@@ -13,10 +14,21 @@ import (
 type PoseEngine struct {
 	session *ort.Session
 	config  Config
+	io      vision.ModelIO
 }
 
 // NewPoseEngine 初始化姿态引擎
 func NewPoseEngine(cfg Config) (*PoseEngine, error) {
+	// 读取模型信息，补全并校验模型参数
+	info, err := vision.ReadModelInfo(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	mio, err := applyModelInfo(&cfg, vision.TaskPose, info)
+	if err != nil {
+		return nil, err
+	}
+
 	oc := new(vision.OnnxConfig)
 	if err := convertutil.CopyProperties(cfg, oc); err != nil {
 		return nil, fmt.Errorf("复制参数失败: %w", err)
@@ -35,6 +47,7 @@ func NewPoseEngine(cfg Config) (*PoseEngine, error) {
 	return &PoseEngine{
 		session: session,
 		config:  cfg,
+		io:      mio,
 	}, nil
 }
 
@@ -45,27 +58,74 @@ func (e *PoseEngine) Destroy() {
 	}
 }
 
+// Names 类别名称表，来自配置或模型元数据，未知时为 nil
+func (e *PoseEngine) Names() []string {
+	return e.config.Names
+}
+
 // Predict 执行姿态估计
 func (e *PoseEngine) Predict(img image.Image) ([]PoseResult, error) {
+	return e.PredictContext(context.Background(), img)
+}
+
+// PredictContext 执行姿态估计，在预处理、推理、后处理之间检查 ctx
+//
+// ctx 结束时返回 *vision.CanceledError，可通过 errors.Is(err, context.DeadlineExceeded) 判断
+func (e *PoseEngine) PredictContext(ctx context.Context, img image.Image) ([]PoseResult, error) {
+	results, err := e.PredictBatchContext(ctx, []image.Image{img})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// PredictBatch 批量执行姿态估计，返回的结果与 imgs 一一对应
+//
+// 模型输入的批大小为动态维度时，图片打包为 [N, 3, S, S] 的输入推理，每次最多 Config.MaxBatch 张 (0 表示一次推理所有图片)；
+// 批大小固定为 N 时每次推理 N 张，不足 N 张时补齐
+func (e *PoseEngine) PredictBatch(imgs []image.Image) ([][]PoseResult, error) {
+	return e.PredictBatchContext(context.Background(), imgs)
+}
+
+// PredictBatchContext 批量执行姿态估计，在预处理、推理、后处理之间检查 ctx
+func (e *PoseEngine) PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]PoseResult, error) {
+	return vision.PredictBatch(imgs, e.io.BatchSize, e.config.MaxBatch, func(batch []image.Image) ([][]PoseResult, error) {
+		return e.run(ctx, batch)
+	})
+}
+
+// run 将 imgs 打包为一个输入执行推理
+func (e *PoseEngine) run(ctx context.Context, imgs []image.Image) ([][]PoseResult, error) {
+	if err := vision.CheckContext(ctx, vision.StagePreprocess); err != nil {
+		return nil, err
+	}
+
 	// 预处理
-	inputTensor, params, err := preprocess(img, e.config.InputSize)
+	inputTensor, params, err := vision.PreprocessBatch(imgs, e.config.InputSize, e.config.Letterbox, e.config.PadValue)
 	if err != nil {
 		return nil, fmt.Errorf("预处理失败: %w", err)
 	}
 	defer inputTensor.Destroy()
 
 	// 推理
+	if err := vision.CheckContext(ctx, vision.StageInference); err != nil {
+		return nil, err
+	}
 	inputValues := map[string]*ort.Value{
-		"images": inputTensor,
+		e.io.Input: inputTensor,
 	}
 	outputValues, err := e.session.Run(inputValues)
 	if err != nil {
 		return nil, fmt.Errorf("推理失败: %w", err)
 	}
-	outputValue := outputValues["output0"]
+	outputValue := outputValues[e.io.Output]
 	defer outputValue.Destroy()
 
-	// Output Shaper: [1, 56, 8400]
+	if err := vision.CheckContext(ctx, vision.StagePostprocess); err != nil {
+		return nil, err
+	}
+
+	// Output Shape: [N, 56, 8400]
 	data, err := ort.GetTensorData[float32](outputValue)
 	if err != nil {
 		return nil, fmt.Errorf("获取输出数据失败: %w", err)
@@ -75,19 +135,26 @@ func (e *PoseEngine) Predict(img image.Image) ([]PoseResult, error) {
 		return nil, fmt.Errorf("获取输出形状失败: %w", err)
 	}
 
-	// 后处理
-	return e.postprocess(data, shape, params)
+	// 后处理，按图片拆分输出
+	results := make([][]PoseResult, len(imgs))
+	for i := range imgs {
+		itemData, itemShape := vision.BatchItem(data, shape, i)
+		if results[i], err = e.postprocess(itemData, itemShape, params[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
 }
 
 // postprocess 后处理
-func (e *PoseEngine) postprocess(data []float32, shape []int64, params imageParams) ([]PoseResult, error) {
+func (e *PoseEngine) postprocess(data []float32, shape []int64, params vision.LetterboxParams) ([]PoseResult, error) {
 	numChannels := int(shape[1])
 	numAnchors := int(shape[2])
 
 	// 解析候选框
 	candidates := e.parseCandidates(data, numChannels, numAnchors, params)
 	// NMS
-	keptIndices := nms(candidates, e.config.IOUThreshold)
+	keptIndices := nms(candidates, e.config.IOUThreshold, e.config.AgnosticNMS)
 
 	results := make([]PoseResult, 0, len(keptIndices))
 	for _, idx := range keptIndices {
@@ -97,6 +164,7 @@ func (e *PoseEngine) postprocess(data []float32, shape []int64, params imagePara
 
 		results = append(results, PoseResult{
 			ClassID:   cand.classID,
+			ClassName: vision.ClassName(e.config.Names, cand.classID),
 			Score:     cand.score,
 			Box:       cand.origBox,
 			KeyPoints: kpts,
@@ -107,7 +175,7 @@ func (e *PoseEngine) postprocess(data []float32, shape []int64, params imagePara
 }
 
 // parseCandidates 解析候选框
-func (e *PoseEngine) parseCandidates(data []float32, channels, anchors int, params imageParams) []candidate {
+func (e *PoseEngine) parseCandidates(data []float32, channels, anchors int, params vision.LetterboxParams) []candidate {
 	// data
 	// T [cx, cy, w, h, c1, x1,y1,conf1...x17,y17,conf17]
 
@@ -150,10 +218,10 @@ func (e *PoseEngine) parseCandidates(data []float32, channels, anchors int, para
 		y1 := cy - h/2
 		x2 := cx + w/2
 		y2 := cy + h/2
-		origX1 := int(x1 / params.scale)
-		origY1 := int(y1 / params.scale)
-		origX2 := int(x2 / params.scale)
-		origY2 := int(y2 / params.scale)
+		origX1 := int(params.OrigX(x1))
+		origY1 := int(params.OrigY(y1))
+		origX2 := int(params.OrigX(x2))
+		origY2 := int(params.OrigY(y2))
 
 		// 暂存 Raw KeyPoints 数据
 		rawKpts := make([]float32, numKptValues)
@@ -173,7 +241,7 @@ func (e *PoseEngine) parseCandidates(data []float32, channels, anchors int, para
 }
 
 // decodeKeyPoints 关键点解码
-func (e *PoseEngine) decodeKeyPoints(raw []float32, params imageParams) []KeyPoint {
+func (e *PoseEngine) decodeKeyPoints(raw []float32, params vision.LetterboxParams) []KeyPoint {
 	kpts := make([]KeyPoint, e.config.NumKeyPoints)
 
 	for i := 0; i < e.config.NumKeyPoints; i++ {
@@ -183,8 +251,8 @@ func (e *PoseEngine) decodeKeyPoints(raw []float32, params imageParams) []KeyPoi
 		conf := raw[idx+2]
 
 		// 坐标映射回原图
-		origX := min(max(0, int(x/params.scale)), params.origW)
-		origY := min(max(0, int(y/params.scale)), params.origH)
+		origX := min(max(0, int(params.OrigX(x))), params.OrigW)
+		origY := min(max(0, int(params.OrigY(y))), params.OrigH)
 
 		kpts[i] = KeyPoint{
 			X:     origX,
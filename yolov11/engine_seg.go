@@ -1,12 +1,12 @@
 package yolov11
 
 import (
+	"context"
 	"fmt"
 	"github.com/getcharzp/go-vision"
 	ort "github.com/getcharzp/onnxruntime_purego"
 	"github.com/up-zero/gotool/convertutil"
 	"image"
-	"image/color"
 	"log"
 )
 
@@ -14,10 +14,21 @@ import (
 type SegEngine struct {
 	session *ort.Session
 	config  Config
+	io      vision.ModelIO
 }
 
 // NewSegEngine 初始化分割引擎
 func NewSegEngine(cfg Config) (*SegEngine, error) {
+	// 读取模型信息，补全并校验模型参数
+	info, err := vision.ReadModelInfo(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	mio, err := applyModelInfo(&cfg, vision.TaskSegment, info)
+	if err != nil {
+		return nil, err
+	}
+
 	oc := new(vision.OnnxConfig)
 	if err := convertutil.CopyProperties(cfg, oc); err != nil {
 		return nil, fmt.Errorf("复制参数失败: %w", err)
@@ -36,6 +47,7 @@ func NewSegEngine(cfg Config) (*SegEngine, error) {
 	return &SegEngine{
 		session: session,
 		config:  cfg,
+		io:      mio,
 	}, nil
 }
 
@@ -46,18 +58,66 @@ func (e *SegEngine) Destroy() {
 	}
 }
 
+// Names 类别名称表，来自配置或模型元数据，未知时为 nil
+func (e *SegEngine) Names() []string {
+	return e.config.Names
+}
+
+// MaskResolution 结果中 Mask 的分辨率
+func (e *SegEngine) MaskResolution() vision.MaskResolution {
+	return e.config.MaskResolution
+}
+
 // Predict 执行分割推理
 func (e *SegEngine) Predict(img image.Image) ([]SegResult, error) {
+	return e.PredictContext(context.Background(), img)
+}
+
+// PredictContext 执行分割推理，在预处理、推理、后处理之间检查 ctx
+//
+// ctx 结束时返回 *vision.CanceledError，可通过 errors.Is(err, context.DeadlineExceeded) 判断
+func (e *SegEngine) PredictContext(ctx context.Context, img image.Image) ([]SegResult, error) {
+	results, err := e.PredictBatchContext(ctx, []image.Image{img})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// PredictBatch 批量执行分割推理，返回的结果与 imgs 一一对应
+//
+// 模型输入的批大小为动态维度时，图片打包为 [N, 3, S, S] 的输入推理，每次最多 Config.MaxBatch 张 (0 表示一次推理所有图片)；
+// 批大小固定为 N 时每次推理 N 张，不足 N 张时补齐
+func (e *SegEngine) PredictBatch(imgs []image.Image) ([][]SegResult, error) {
+	return e.PredictBatchContext(context.Background(), imgs)
+}
+
+// PredictBatchContext 批量执行分割推理，在预处理、推理、后处理之间检查 ctx
+func (e *SegEngine) PredictBatchContext(ctx context.Context, imgs []image.Image) ([][]SegResult, error) {
+	return vision.PredictBatch(imgs, e.io.BatchSize, e.config.MaxBatch, func(batch []image.Image) ([][]SegResult, error) {
+		return e.run(ctx, batch)
+	})
+}
+
+// run 将 imgs 打包为一个输入执行推理
+func (e *SegEngine) run(ctx context.Context, imgs []image.Image) ([][]SegResult, error) {
+	if err := vision.CheckContext(ctx, vision.StagePreprocess); err != nil {
+		return nil, err
+	}
+
 	// 预处理
-	inputTensor, params, err := preprocess(img, e.config.InputSize)
+	inputTensor, params, err := vision.PreprocessBatch(imgs, e.config.InputSize, e.config.Letterbox, e.config.PadValue)
 	if err != nil {
 		return nil, fmt.Errorf("预处理失败: %w", err)
 	}
 	defer inputTensor.Destroy()
 
 	// 推理
+	if err := vision.CheckContext(ctx, vision.StageInference); err != nil {
+		return nil, err
+	}
 	inputValues := map[string]*ort.Value{
-		"images": inputTensor,
+		e.io.Input: inputTensor,
 	}
 	outputValues, err := e.session.Run(inputValues)
 	if err != nil {
@@ -69,40 +129,58 @@ func (e *SegEngine) Predict(img image.Image) ([]SegResult, error) {
 		}
 	}()
 
-	// output0: Detections [1, 116, 8400]
-	// output1: Mask Protos [1, 32, 160, 160]
-
-	// 后处理
-	return e.postprocess(outputValues["output0"], outputValues["output1"], params)
-}
+	if err := vision.CheckContext(ctx, vision.StagePostprocess); err != nil {
+		return nil, err
+	}
 
-// postprocess 后处理
-func (e *SegEngine) postprocess(out0, out1 *ort.Value, params imageParams) ([]SegResult, error) {
+	// output0: Detections [N, 116, 8400]
+	// output1: Mask Protos [N, 32, 160, 160]
+	out0, out1 := outputValues[e.io.Output], outputValues[e.io.Protos]
 	data0, err := ort.GetTensorData[float32](out0)
 	if err != nil {
 		return nil, fmt.Errorf("获取输出数据失败: %w", err)
 	}
-	shape0, err := out0.GetShape() // [1, 116, 8400]
+	shape0, err := out0.GetShape()
 	if err != nil {
 		return nil, fmt.Errorf("获取输出形状失败: %w", err)
 	}
-	numChannels := int(shape0[1]) // 4 (box) + 80 (cls) + 32 (mask) = 116
-	numAnchors := int(shape0[2])  // 8400
-
 	data1, err := ort.GetTensorData[float32](out1)
 	if err != nil {
-		return nil, fmt.Errorf("获取输出数据失败: %w", err)
+		return nil, fmt.Errorf("获取 Mask Protos 失败: %w", err)
 	}
-	shape1, err := out1.GetShape() // [1, 32, 160, 160]
+	shape1, err := out1.GetShape()
 	if err != nil {
 		return nil, fmt.Errorf("获取输出形状失败: %w", err)
 	}
-	protoC, protoH, protoW := int(shape1[1]), int(shape1[2]), int(shape1[3])
+
+	// 后处理，按图片拆分输出
+	results := make([][]SegResult, len(imgs))
+	for i := range imgs {
+		dets, detsShape := vision.BatchItem(data0, shape0, i)
+		protos, protosShape := vision.BatchItem(data1, shape1, i)
+		if results[i], err = e.postprocess(ctx, dets, detsShape, protos, protosShape, params[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// postprocess 后处理
+//
+// # Params:
+//
+//	data0, shape0: 单张图片的检测输出，形状为 [1, 116, 8400]
+//	protoData, shape1: 单张图片的 Mask 原型，形状为 [1, 32, 160, 160]
+//	params: 图片尺寸信息
+func (e *SegEngine) postprocess(ctx context.Context, data0 []float32, shape0 []int64, protoData []float32, shape1 []int64, params vision.LetterboxParams) ([]SegResult, error) {
+	numChannels := int(shape0[1]) // 4 (box) + 80 (cls) + 32 (mask) = 116
+	numAnchors := int(shape0[2])  // 8400
+	protos := vision.MaskProtos{Data: protoData, C: int(shape1[1]), H: int(shape1[2]), W: int(shape1[3])}
 
 	// 解析候选框
 	candidates := e.parseCandidates(data0, numChannels, numAnchors, params)
 	// NMS
-	keptIndices := nms(candidates, e.config.IOUThreshold)
+	keptIndices := nms(candidates, e.config.IOUThreshold, e.config.AgnosticNMS)
 
 	results := make([]SegResult, 0, len(keptIndices))
 
@@ -110,13 +188,20 @@ func (e *SegEngine) postprocess(out0, out1 *ort.Value, params imageParams) ([]Se
 	for _, idx := range keptIndices {
 		cand := candidates[idx]
 
+		// 逐个实例解码 Mask 耗时较长，每个实例之前检查 ctx
+		if err := vision.CheckContext(ctx, vision.StagePostprocess); err != nil {
+			return nil, err
+		}
+
 		// 生成二进制 mask
-		mask := e.decodeMask(cand, data1, protoC, protoH, protoW, params)
+		mask, maskSize := e.decodeMask(cand.origBox, cand.maskCoeffs, protos, params)
 		results = append(results, SegResult{
-			ClassID: cand.classID,
-			Score:   cand.score,
-			Box:     cand.origBox, // image.Rectangle
-			Mask:    mask,
+			ClassID:   cand.classID,
+			ClassName: vision.ClassName(e.config.Names, cand.classID),
+			Score:     cand.score,
+			Box:       cand.origBox, // image.Rectangle
+			Mask:      mask,
+			MaskSize:  maskSize,
 		})
 	}
 
@@ -139,7 +224,7 @@ func (e *SegEngine) postprocess(out0, out1 *ort.Value, params imageParams) ([]Se
 //	channels: 模型输出的通道数
 //	anchors: 模型输出的锚点数
 //	params: 图片尺寸信息
-func (e *SegEngine) parseCandidates(data []float32, channels, anchors int, params imageParams) []candidate {
+func (e *SegEngine) parseCandidates(data []float32, channels, anchors int, params vision.LetterboxParams) []candidate {
 	var cands []candidate
 
 	// 检查通道数
@@ -181,10 +266,10 @@ func (e *SegEngine) parseCandidates(data []float32, channels, anchors int, param
 		y1 := cy - h/2
 		x2 := cx + w/2
 		y2 := cy + h/2
-		origX1 := max(0, int(x1/params.scale))
-		origY1 := max(0, int(y1/params.scale))
-		origX2 := min(params.origW, int(x2/params.scale))
-		origY2 := min(params.origH, int(y2/params.scale))
+		origX1 := max(0, int(params.OrigX(x1)))
+		origY1 := max(0, int(params.OrigY(y1)))
+		origX2 := min(params.OrigW, int(params.OrigX(x2)))
+		origY2 := min(params.OrigH, int(params.OrigY(y2)))
 
 		cands = append(cands, candidate{
 			box:        [4]float32{x1, y1, x2, y2},
@@ -199,45 +284,22 @@ func (e *SegEngine) parseCandidates(data []float32, channels, anchors int, param
 
 // decodeMask Mask解码
 //
+// 在原型分辨率上计算一次 Mask，双线性插值到 MaskResolution 指定的分辨率后在检测框内二值化，
+// 返回只保存检测框区域的 Mask，以及 Mask 坐标系下整幅图片的尺寸
+//
 // # Params:
 //
-//	cand: 候选结果
-//	protos: 模型输出的 Mask 原型图
-//	c: 原型掩码的通道数
-//	h: 单个原型掩码的高度
-//	w: 单个原型掩码的宽度
+//	origBox: 原图上的检测框
+//	coeffs: 当前检测框对应的 Mask 系数
+//	protos: 模型输出的 Mask 原型
 //	params: 图片尺寸信息
-func (e *SegEngine) decodeMask(cand candidate, protos []float32, c, h, w int, params imageParams) *image.Gray {
-	finalMask := image.NewGray(image.Rect(0, 0, params.origW, params.origH))
-
-	// Mask 原型图相对于 InputSize(640) 的缩放比例
-	maskStride := float32(e.config.InputSize) / float32(w)
-
-	// 遍历原图上的 Box 区域
-	origBox := cand.origBox
-	coeffs := cand.maskCoeffs
-	for y := origBox.Min.Y; y < origBox.Max.Y; y++ {
-		for x := origBox.Min.X; x < origBox.Max.X; x++ {
-			// 映射回 640 尺度
-			inputX := float32(x) * params.scale
-			inputY := float32(y) * params.scale
-
-			// 映射回 160 Mask 尺度
-			mx := int(inputX / maskStride)
-			my := int(inputY / maskStride)
-
-			if mx >= 0 && mx < w && my >= 0 && my < h {
-				// 计算该像素的 Mask 值 (Dot Product)
-				sum := float32(0.0)
-				for k := 0; k < c; k++ {
-					sum += coeffs[k] * protos[k*h*w+my*w+mx]
-				}
-
-				if sigmoid(sum) > e.config.MaskThreshold {
-					finalMask.SetGray(x, y, color.Gray{Y: 255})
-				}
-			}
-		}
-	}
-	return finalMask
+func (e *SegEngine) decodeMask(origBox image.Rectangle, coeffs []float32, protos vision.MaskProtos, params vision.LetterboxParams) (*image.Gray, image.Point) {
+	// Mask 原型相对于 InputSize 的步长，例如 640/160 = 4
+	stride := float32(e.config.InputSize) / float32(protos.W)
+	size, box, t := params.MaskLayout(e.config.MaskResolution, origBox, stride)
+
+	// 只保存检测框区域的 Mask
+	mask := image.NewGray(box.Intersect(image.Rect(0, 0, size.X, size.Y)))
+	protos.Decode(mask, coeffs, box, t, e.config.MaskThreshold)
+	return mask, size
 }
@@ -0,0 +1,159 @@
+package yolov11
+
+import (
+	"fmt"
+	"github.com/getcharzp/go-vision"
+)
+
+// applyModelInfo 根据模型信息补全并校验配置中的模型参数
+//
+// # Params:
+//
+//	cfg: 引擎配置，InputSize、NumClasses、NumMaskCoeffs、NumKeyPoints 为 0 时从模型中读取
+//	task: 引擎对应的任务类型，例如 vision.TaskDetect
+//	info: vision.ReadModelInfo 读取的模型信息
+func applyModelInfo(cfg *Config, task string, info *vision.ModelInfo) (vision.ModelIO, error) {
+	mio, err := info.ResolveInput(task, &cfg.InputSize)
+	if err != nil {
+		return mio, err
+	}
+
+	// 类别名称优先使用配置，其次使用模型元数据
+	names := info.ClassNames(cfg.Names)
+	numNames := len(names)
+
+	switch task {
+	case vision.TaskClassify:
+		// 输出 [N, nc]
+		if len(info.Outputs) != 1 || len(info.Outputs[0].Shape) != 2 {
+			return mio, fmt.Errorf("%w: 分类模型应有 1 个形状为 [N, nc] 的输出", vision.ErrModelMismatch)
+		}
+		out := info.Outputs[0]
+		mio.Output = out.Name
+		if err := vision.ResolveParam("NumClasses", &cfg.NumClasses, int(out.Dim(1)), numNames); err != nil {
+			return mio, err
+		}
+
+	case vision.TaskSegment:
+		// output0 [N, 4+nc+nm, anchors], output1 [N, nm, h, w]
+		if len(info.Outputs) != 2 {
+			return mio, fmt.Errorf("%w: 分割模型应有 2 个输出，实际 %d 个", vision.ErrModelMismatch, len(info.Outputs))
+		}
+		det, protos := info.Outputs[0], info.Outputs[1]
+		if len(det.Shape) == 4 {
+			det, protos = protos, det
+		}
+		if len(det.Shape) != 3 || len(protos.Shape) != 4 {
+			return mio, fmt.Errorf("%w: 分割模型输出形状应为 [N, C, A] 和 [N, nm, H, W]，实际 %v 和 %v",
+				vision.ErrModelMismatch, det.Shape, protos.Shape)
+		}
+		mio.Output, mio.Protos = det.Name, protos.Name
+
+		if err := vision.ResolveParam("NumMaskCoeffs", &cfg.NumMaskCoeffs, int(protos.Dim(1))); err != nil {
+			return mio, err
+		}
+		if cfg.NumMaskCoeffs <= 0 {
+			return mio, fmt.Errorf("%w: 无法确定 Mask 系数数量", vision.ErrModelMismatch)
+		}
+		numClasses := -1
+		if c := det.Dim(1); c > 0 {
+			numClasses = int(c) - 4 - cfg.NumMaskCoeffs
+		}
+		if err := vision.ResolveParam("NumClasses", &cfg.NumClasses, numClasses, numNames); err != nil {
+			return mio, err
+		}
+		if err := checkChannels(det, 4+cfg.NumClasses+cfg.NumMaskCoeffs); err != nil {
+			return mio, err
+		}
+
+	case vision.TaskPose:
+		// output0 [N, 4+nc+nk*3, anchors]
+		out, err := info.SingleOutput("姿态", 3)
+		if err != nil {
+			return mio, err
+		}
+		mio.Output = out.Name
+
+		numKeyPoints := 0
+		if k, d, ok := info.KeyPointShape(); ok {
+			if d != 3 {
+				return mio, fmt.Errorf("%w: 暂不支持每个关键点 %d 个数值的模型 (需要 x, y, conf)", vision.ErrModelMismatch, d)
+			}
+			numKeyPoints = k
+		}
+		if err := vision.ResolveParam("NumKeyPoints", &cfg.NumKeyPoints, numKeyPoints); err != nil {
+			return mio, err
+		}
+		if err := vision.ResolveParam("NumClasses", &cfg.NumClasses, numNames); err != nil {
+			return mio, err
+		}
+		// 未指定时默认为单类别 (person)
+		if cfg.NumClasses == 0 && cfg.NumKeyPoints == 0 {
+			cfg.NumClasses = 1
+		}
+		if channels := int(out.Dim(1)); channels > 0 {
+			if cfg.NumKeyPoints == 0 {
+				cfg.NumKeyPoints = (channels - 4 - cfg.NumClasses) / 3
+			}
+			if cfg.NumClasses == 0 {
+				cfg.NumClasses = channels - 4 - cfg.NumKeyPoints*3
+			}
+			if channels != 4+cfg.NumClasses+cfg.NumKeyPoints*3 {
+				return mio, fmt.Errorf("%w: 输出通道数 %d 与类别数 %d、关键点数 %d 不符",
+					vision.ErrModelMismatch, channels, cfg.NumClasses, cfg.NumKeyPoints)
+			}
+		} else if cfg.NumKeyPoints == 0 {
+			cfg.NumKeyPoints = 17
+		}
+
+	case vision.TaskOBB:
+		// output0 [N, 4+nc+1, anchors]
+		out, err := info.SingleOutput("旋转目标检测", 3)
+		if err != nil {
+			return mio, err
+		}
+		mio.Output = out.Name
+		numClasses := -1
+		if c := out.Dim(1); c > 0 {
+			numClasses = int(c) - 5
+		}
+		if err := vision.ResolveParam("NumClasses", &cfg.NumClasses, numClasses, numNames); err != nil {
+			return mio, err
+		}
+		if err := checkChannels(out, 4+cfg.NumClasses+1); err != nil {
+			return mio, err
+		}
+
+	default:
+		// output0 [N, 4+nc, anchors]
+		out, err := info.SingleOutput("检测", 3)
+		if err != nil {
+			return mio, err
+		}
+		mio.Output = out.Name
+		numClasses := -1
+		if c := out.Dim(1); c > 0 {
+			numClasses = int(c) - 4
+		}
+		if err := vision.ResolveParam("NumClasses", &cfg.NumClasses, numClasses, numNames); err != nil {
+			return mio, err
+		}
+		if err := checkChannels(out, 4+cfg.NumClasses); err != nil {
+			return mio, err
+		}
+	}
+
+	if cfg.NumClasses <= 0 {
+		return mio, fmt.Errorf("%w: 无法确定类别数量", vision.ErrModelMismatch)
+	}
+	cfg.Names = vision.ResolveNames(task, cfg.NumClasses, names)
+	return mio, nil
+}
+
+// checkChannels 校验输出的通道数
+func checkChannels(out vision.TensorInfo, expected int) error {
+	if c := out.Dim(1); c > 0 && int(c) != expected {
+		return fmt.Errorf("%w: 输出 %s 的通道数为 %d，预期 %d", vision.ErrModelMismatch, out.Name, c, expected)
+	}
+	return nil
+}
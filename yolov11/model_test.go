@@ -0,0 +1,143 @@
+package yolov11
+
+import (
+	"errors"
+	"github.com/getcharzp/go-vision"
+	"testing"
+)
+
+func newModelInfo(metadata map[string]string, outputs ...vision.TensorInfo) *vision.ModelInfo {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return &vision.ModelInfo{
+		Inputs:   []vision.TensorInfo{{Name: "images", Shape: []int64{1, 3, 640, 640}}},
+		Outputs:  outputs,
+		Metadata: metadata,
+	}
+}
+
+func TestApplyModelInfo_Detect(t *testing.T) {
+	info := newModelInfo(map[string]string{"task": "detect", "names": "{0: 'cat', 1: 'dog', 2: 'bird'}"},
+		vision.TensorInfo{Name: "output0", Shape: []int64{1, 7, 8400}})
+
+	cfg := DefaultDetConfig()
+	mio, err := applyModelInfo(&cfg, vision.TaskDetect, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.InputSize != 640 || cfg.NumClasses != 3 {
+		t.Fatalf("应读取到 InputSize=640 NumClasses=3，实际 %d %d", cfg.InputSize, cfg.NumClasses)
+	}
+	if mio.Input != "images" || mio.Output != "output0" || mio.BatchSize != 1 {
+		t.Fatalf("输入输出信息错误: %+v", mio)
+	}
+	if vision.ClassName(cfg.Names, 1) != "dog" {
+		t.Fatalf("应使用模型元数据中的类别名称，实际 %q", cfg.Names)
+	}
+
+	// 配置的类别名称优先于模型元数据
+	cfg = DefaultDetConfig()
+	cfg.Names = []string{"a", "b", "c"}
+	if _, err := applyModelInfo(&cfg, vision.TaskDetect, info); err != nil || cfg.Names[1] != "b" {
+		t.Fatalf("应使用配置的类别名称，实际 %q %v", cfg.Names, err)
+	}
+	cfg = DefaultDetConfig()
+	cfg.Names = []string{"a", "b"}
+	if _, err := applyModelInfo(&cfg, vision.TaskDetect, info); !errors.Is(err, vision.ErrModelMismatch) {
+		t.Fatalf("配置的类别名称数量与模型不一致时应返回 ErrModelMismatch，实际 %v", err)
+	}
+
+	cfg = DefaultDetConfig()
+	cfg.NumClasses = 80
+	if _, err := applyModelInfo(&cfg, vision.TaskDetect, info); !errors.Is(err, vision.ErrModelMismatch) {
+		t.Fatalf("配置的类别数与模型不一致时应返回 ErrModelMismatch，实际 %v", err)
+	}
+}
+
+func TestApplyModelInfo_Segment(t *testing.T) {
+	// 输出顺序与常规导出相反
+	info := newModelInfo(nil,
+		vision.TensorInfo{Name: "protos", Shape: []int64{1, 32, 160, 160}},
+		vision.TensorInfo{Name: "dets", Shape: []int64{1, 116, 8400}})
+
+	cfg := DefaultSegConfig()
+	mio, err := applyModelInfo(&cfg, vision.TaskSegment, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.NumClasses != 80 || cfg.NumMaskCoeffs != 32 {
+		t.Fatalf("应读取到 NumClasses=80 NumMaskCoeffs=32，实际 %d %d", cfg.NumClasses, cfg.NumMaskCoeffs)
+	}
+	if mio.Output != "dets" || mio.Protos != "protos" {
+		t.Fatalf("输出名称错误: %+v", mio)
+	}
+	if vision.ClassName(cfg.Names, 0) != "person" {
+		t.Fatalf("元数据中没有类别名称时应使用 COCO 类别表，实际 %q", cfg.Names)
+	}
+
+	// 检测模型不能用于分割引擎
+	det := newModelInfo(nil, vision.TensorInfo{Name: "output0", Shape: []int64{1, 84, 8400}})
+	cfg = DefaultSegConfig()
+	if _, err := applyModelInfo(&cfg, vision.TaskSegment, det); !errors.Is(err, vision.ErrModelMismatch) {
+		t.Fatalf("检测模型用于分割引擎时应返回 ErrModelMismatch，实际 %v", err)
+	}
+}
+
+func TestApplyModelInfo_Pose(t *testing.T) {
+	info := newModelInfo(map[string]string{"kpt_shape": "[5, 3]"},
+		vision.TensorInfo{Name: "output0", Shape: []int64{1, 20, 8400}})
+
+	cfg := DefaultPoseConfig()
+	if _, err := applyModelInfo(&cfg, vision.TaskPose, info); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.NumClasses != 1 || cfg.NumKeyPoints != 5 {
+		t.Fatalf("应读取到 NumClasses=1 NumKeyPoints=5，实际 %d %d", cfg.NumClasses, cfg.NumKeyPoints)
+	}
+	if vision.ClassName(cfg.Names, 0) != "person" {
+		t.Fatalf("姿态模型默认类别应为 person，实际 %q", cfg.Names)
+	}
+}
+
+func TestApplyModelInfo_TaskMismatch(t *testing.T) {
+	info := newModelInfo(map[string]string{"task": "obb"},
+		vision.TensorInfo{Name: "output0", Shape: []int64{1, 20, 21504}})
+
+	cfg := DefaultDetConfig()
+	if _, err := applyModelInfo(&cfg, vision.TaskDetect, info); !errors.Is(err, vision.ErrModelMismatch) {
+		t.Fatalf("OBB 模型用于检测引擎时应返回 ErrModelMismatch，实际 %v", err)
+	}
+
+	cfg = DefaultOBBConfig()
+	if _, err := applyModelInfo(&cfg, vision.TaskOBB, info); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.NumClasses != 15 || vision.ClassName(cfg.Names, 0) != "plane" {
+		t.Fatalf("应读取到 NumClasses=15 并使用 DOTA 类别表，实际 %d %q", cfg.NumClasses, cfg.Names)
+	}
+}
+
+func TestApplyModelInfo_DynamicInput(t *testing.T) {
+	info := newModelInfo(map[string]string{"imgsz": "[1024, 1024]"},
+		vision.TensorInfo{Name: "output0", Shape: []int64{-1, 84, -1}})
+	info.Inputs[0].Shape = []int64{-1, 3, -1, -1}
+
+	cfg := DefaultDetConfig()
+	mio, err := applyModelInfo(&cfg, vision.TaskDetect, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mio.BatchSize != 0 {
+		t.Fatal("批大小为动态维度时应支持批量推理")
+	}
+	if cfg.InputSize != 1024 {
+		t.Fatalf("动态输入时应使用 imgsz，实际 %d", cfg.InputSize)
+	}
+
+	cfg = DefaultDetConfig()
+	cfg.InputSize = 1280
+	if _, err := applyModelInfo(&cfg, vision.TaskDetect, info); err != nil || cfg.InputSize != 1280 {
+		t.Fatalf("动态输入时应允许自定义 InputSize，实际 %d %v", cfg.InputSize, err)
+	}
+}
@@ -0,0 +1,159 @@
+package yolov11
+
+import (
+	"context"
+	"errors"
+	"github.com/getcharzp/go-vision"
+	"image"
+	"math"
+	"testing"
+)
+
+func TestNMS_ClassAware(t *testing.T) {
+	// person 与 handbag 的检测框高度重叠
+	cands := []candidate{
+		{origBox: image.Rect(100, 100, 300, 500), score: 0.9, classID: 0},
+		{origBox: image.Rect(110, 110, 300, 480), score: 0.8, classID: 26},
+		{origBox: image.Rect(105, 100, 300, 500), score: 0.7, classID: 0},
+	}
+
+	keep := nms(cands, 0.5, false)
+	if len(keep) != 2 {
+		t.Fatalf("按类别 NMS 应保留 2 个检测框，实际 %d 个", len(keep))
+	}
+	classes := map[int]bool{}
+	for _, idx := range keep {
+		classes[cands[idx].classID] = true
+	}
+	if !classes[0] || !classes[26] {
+		t.Fatalf("按类别 NMS 应同时保留 person 和 handbag，实际 %v", classes)
+	}
+}
+
+func TestNMS_Agnostic(t *testing.T) {
+	cands := []candidate{
+		{origBox: image.Rect(100, 100, 300, 500), score: 0.8, classID: 26},
+		{origBox: image.Rect(110, 110, 300, 480), score: 0.9, classID: 0},
+		{origBox: image.Rect(600, 600, 700, 700), score: 0.6, classID: 26},
+	}
+
+	keep := nms(cands, 0.5, true)
+	if len(keep) != 2 {
+		t.Fatalf("跨类别 NMS 应保留 2 个检测框，实际 %d 个", len(keep))
+	}
+	if cands[keep[0]].classID != 0 || cands[keep[0]].score != 0.9 {
+		t.Fatalf("应优先保留得分最高的检测框，实际 %+v", cands[keep[0]])
+	}
+	if cands[keep[1]].origBox != image.Rect(600, 600, 700, 700) {
+		t.Fatalf("不重叠的检测框不应被抑制，实际 %+v", cands[keep[1]])
+	}
+}
+
+func TestDetEngine_PostprocessNMS(t *testing.T) {
+	const (
+		numClasses = 3
+		numAnchors = 3
+	)
+	// [cx, cy, w, h, c0, c1, c2] x anchors
+	anchors := [][]float32{
+		{200, 300, 200, 400, 0.9, 0, 0},
+		{205, 295, 190, 370, 0, 0, 0.8},
+		{210, 300, 200, 400, 0.7, 0, 0},
+	}
+	channels := 4 + numClasses
+	data := make([]float32, channels*numAnchors)
+	for i, a := range anchors {
+		for c, v := range a {
+			data[c*numAnchors+i] = v
+		}
+	}
+	shape := []int64{1, int64(channels), numAnchors}
+	params := vision.LetterboxParams{OrigW: 640, OrigH: 640, Scale: 1}
+
+	cfg := DefaultDetConfig()
+	cfg.NumClasses = numClasses
+	e := &DetEngine{config: cfg}
+
+	results, err := e.postprocess(data, shape, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("按类别 NMS 应保留 2 个结果，实际 %d 个", len(results))
+	}
+
+	e.config.AgnosticNMS = true
+	results, err = e.postprocess(data, shape, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ClassID != 0 {
+		t.Fatalf("跨类别 NMS 应仅保留 class 0，实际 %+v", results)
+	}
+}
+
+func TestNMSRotated(t *testing.T) {
+	// 斜向并排的两个旋转框：外接矩形 IOU 很高，但旋转框不相交
+	cands := []candidate{
+		{box: [4]float32{100, 100, 200, 20}, angle: math.Pi / 4, score: 0.9},
+		{box: [4]float32{118, 82, 200, 20}, angle: math.Pi / 4, score: 0.8},
+		{box: [4]float32{101, 101, 200, 20}, angle: math.Pi / 4, score: 0.7},
+	}
+	for i := range cands {
+		cands[i].origBox = image.Rect(22, 22, 178, 178)
+	}
+
+	if keep := nms(cands, 0.5, false); len(keep) != 1 {
+		t.Fatalf("外接矩形 NMS 应仅保留 1 个检测框，实际 %d 个", len(keep))
+	}
+	keep := nmsRotated(cands, 0.5, false)
+	if len(keep) != 2 {
+		t.Fatalf("旋转框 NMS 应保留 2 个检测框，实际 %d 个", len(keep))
+	}
+	if cands[keep[0]].score != 0.9 || cands[keep[1]].score != 0.8 {
+		t.Fatalf("应保留并排的两个旋转框，实际 %+v", keep)
+	}
+}
+
+func TestDetEngine_PredictContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	e := &DetEngine{config: DefaultDetConfig()}
+	_, err := e.PredictContext(ctx, image.NewRGBA(image.Rect(0, 0, 64, 64)))
+
+	var ce *vision.CanceledError
+	if !errors.As(err, &ce) || ce.Stage != vision.StagePreprocess {
+		t.Fatalf("应在预处理前中止，实际 %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("应可通过 errors.Is 判断 context.Canceled，实际 %v", err)
+	}
+}
+
+func TestSegEngine_DecodeMaskCompact(t *testing.T) {
+	// 单通道 4x4 原型，左两列为正
+	data := make([]float32, 16)
+	for i := range data {
+		if i%4 < 2 {
+			data[i] = 10
+		} else {
+			data[i] = -10
+		}
+	}
+	protos := vision.MaskProtos{Data: data, C: 1, H: 4, W: 4}
+
+	cfg := DefaultSegConfig()
+	cfg.InputSize = 16
+	e := &SegEngine{config: cfg}
+	params := vision.LetterboxParams{OrigW: 16, OrigH: 16, Scale: 1}
+
+	box := image.Rect(4, 4, 12, 8)
+	mask, size := e.decodeMask(box, []float32{1}, protos, params)
+	if mask.Bounds() != box || size != image.Pt(16, 16) {
+		t.Fatalf("Mask 应只保存检测框区域，实际 %v %v", mask.Bounds(), size)
+	}
+	if mask.GrayAt(4, 4).Y != 255 || mask.GrayAt(11, 7).Y != 0 {
+		t.Fatal("Mask 解码结果错误")
+	}
+}